@@ -19,6 +19,31 @@ type ArcI interface {
 	FireTokens()
 }
 
+// Test if arc is enabled when its Place holds 'toks' tokens (used by analysis,
+// that only knows the semantic of Arc and EnableArc)
+func arcEnabledBy(a ArcI, toks int) bool {
+	switch arc := a.(type) {
+	case *Arc:
+		return arc.enabledBy(toks)
+	case *EnableArc:
+		return arc.enabledBy(toks)
+	}
+	logger.Panicf("Arc [%s] has unknown type %T", a.Id(), a)
+	return false
+}
+
+// Tokens moved by arc when its Transition fires (used by analysis)
+func arcWeight(a ArcI) int {
+	switch arc := a.(type) {
+	case *Arc:
+		return arc.weight()
+	case *EnableArc:
+		return arc.weight()
+	}
+	logger.Panicf("Arc [%s] has unknown type %T", a.Id(), a)
+	return 0
+}
+
 type Arc struct {
 	id     string
 	Weight int
@@ -44,13 +69,19 @@ func (a *Arc) Notify() {
 	}
 }
 func (a *Arc) IsEnabled() bool {
-	return a.P.Tokens() >= a.Weight
+	return a.enabledBy(a.P.Tokens())
+}
+func (a *Arc) enabledBy(toks int) bool {
+	return toks >= a.Weight
+}
+func (a *Arc) weight() int {
+	return a.Weight
 }
 func (a *Arc) ConsumeTokens() {
-	a.P.addTokensNoLock(-a.Weight)
+	a.P.addTokensNoLock(-a.weight())
 }
 func (a *Arc) FireTokens() {
-	a.P.addTokensNoLock(a.Weight)
+	a.P.addTokensNoLock(a.weight())
 }
 
 /* Enable Arc type  used to link Transition to Place
@@ -86,7 +117,9 @@ func (a *EnableArc) Transition() TransitionI {
 	return a.T
 }
 func (a *EnableArc) IsEnabled() bool {
-	toks := a.P.Tokens()
+	return a.enabledBy(a.P.Tokens())
+}
+func (a *EnableArc) enabledBy(toks int) bool {
 	if a.low != undef && toks < a.low {
 		return false
 	}
@@ -95,6 +128,9 @@ func (a *EnableArc) IsEnabled() bool {
 	}
 	return true
 }
+func (a *EnableArc) weight() int {
+	return 0 // enable arc never moves tokens
+}
 func (a *EnableArc) ConsumeTokens() {}
 func (a *EnableArc) FireTokens()    {}
 func (a *EnableArc) Notify() {
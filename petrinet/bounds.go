@@ -0,0 +1,44 @@
+package petrinet
+
+import "fmt"
+
+// Max number of tokens each Place can hold in a reachable marking
+// (Omega if the place is unbounded), computed on coverability tree from
+// the current marking. Construction is stopped after 'AnalysisLimit' nodes.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) Bounds() (Marking, error) {
+	c, err := n.CoverabilityTree(AnalysisLimit)
+	if err != nil {
+		return nil, err
+	}
+	bounds := make(Marking, len(n.places))
+	for i, p := range n.places {
+		max := 0
+		for _, s := range c.states {
+			if s[i] > max {
+				max = s[i]
+			}
+		}
+		bounds[p.Id()] = max
+	}
+	return bounds, NoError
+}
+
+// Test if every Place holds at most 'k' tokens in every reachable marking
+func (n *Net) IsKBounded(k int) (bool, error) {
+	bounds, err := n.Bounds()
+	if err != nil {
+		return false, fmt.Errorf("IsKBounded() failed for [%s]: %v", n.id, err)
+	}
+	for _, b := range bounds {
+		if b > k {
+			return false, NoError
+		}
+	}
+	return true, NoError
+}
+
+// Test if every Place holds at most one token in every reachable marking
+func (n *Net) IsSafe() (bool, error) {
+	return n.IsKBounded(1)
+}
@@ -0,0 +1,94 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBounds(test *testing.T) {
+	/* build net:
+
+	(P1)──►[T1]──►(P2)──►[T2]──►(P1)
+	         │
+	         └───►(P3)
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	t1.ConnectTo(p3, 1)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p1, 1)
+	p1.AddTokens(2)
+
+	bounds, err := net.Bounds()
+	assert.NoError(test, err)
+	assert.Equal(test, Marking{"P1": 2, "P2": 2, "P3": Omega}, bounds)
+	bounded, err := net.IsKBounded(2)
+	assert.NoError(test, err)
+	assert.False(test, bounded)
+
+	// P3 emptied only when P1 is empty: still unbounded
+	t3 := net.NewTransition("T3")
+	p3.ConnectTo(t3, 1)
+	t3.InhibitedBy(p1)
+	bounds, err = net.Bounds()
+	assert.NoError(test, err)
+	assert.Equal(test, Marking{"P1": 2, "P2": 2, "P3": Omega}, bounds)
+}
+
+func TestIsSafe(test *testing.T) {
+	// (P1)──►[T1]──►(P2)──►[T2]──►(P1)
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p1, 1)
+	p1.AddTokens(1)
+
+	safe, err := net.IsSafe()
+	assert.NoError(test, err)
+	assert.True(test, safe)
+
+	p1.AddTokens(1)
+	safe, err = net.IsSafe()
+	assert.NoError(test, err)
+	assert.False(test, safe)
+}
+
+func TestBoundsLimit(test *testing.T) {
+	/* build net (P read with an upper bound is never accelerated):
+
+	[Gen]──►(P)
+
+	(P)──o[T]
+
+	*/
+	net := NewNet("TestNet")
+	p := net.NewPlace("P")
+	gen := net.NewTransition("Gen")
+	t := net.NewTransition("T")
+	gen.ConnectTo(p, 1)
+	t.InhibitedBy(p)
+
+	tree, err := net.CoverabilityTree(1000)
+	assert.Error(test, err)
+	assert.Equal(test, 1000, len(tree.Nodes))
+
+	defer func(limit int) { AnalysisLimit = limit }(AnalysisLimit)
+	AnalysisLimit = 1000
+	_, err = net.Bounds()
+	assert.Error(test, err)
+	_, err = net.IsSafe()
+	assert.Error(test, err)
+}
@@ -0,0 +1,108 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// (P)──►[T]──►(Q)──►[U]──►(R)  with Q holding at most 'k' tokens
+func buildCapacityPipeline(k int) (*Net, PlaceI, PlaceI, PlaceI) {
+	net := NewNet("TestNet")
+	p := net.NewPlace("P")
+	q := net.NewPlace("Q")
+	r := net.NewPlace("R")
+	t := net.NewTransition("T")
+	u := net.NewTransition("U")
+	p.ConnectTo(t, 1)
+	t.ConnectTo(q, 1)
+	q.ConnectTo(u, 1)
+	u.ConnectTo(r, 1)
+	q.SetCapacity(k)
+	return net, p, q, r
+}
+
+func TestCapacityStep(test *testing.T) {
+	net, p, q, _ := buildCapacityPipeline(2)
+	t, u := net.transitions[0], net.transitions[1]
+	p.AddTokens(3)
+
+	assert.NoError(test, net.Fire(t))
+	assert.NoError(test, net.Fire(t))
+	// Q is full
+	assert.Equal(test, []TransitionI{u}, net.EnabledTransitions())
+	assert.Error(test, net.Fire(t))
+	assert.NoError(test, net.Fire(u))
+	assert.NoError(test, net.Fire(t))
+	assert.Equal(test, 2, q.Tokens())
+	assert.False(test, q.AddTokens(1))
+	assert.Equal(test, 2, q.Tokens())
+
+	// adding tokens beyond capacity leaves the marking unchanged
+	full := NewNet("TestNet").NewPlace("P")
+	full.SetCapacity(1)
+	assert.False(test, full.AddTokens(2))
+	assert.Equal(test, 0, full.Tokens())
+	assert.True(test, full.AddTokens(1))
+
+	// firing counts tokens consumed from the same place
+	loop := NewNet("TestNet")
+	s := loop.NewPlace("S")
+	tl := loop.NewTransition("T")
+	s.ConnectTo(tl, 1)
+	tl.ConnectTo(s, 1)
+	s.AddTokens(1)
+	s.SetCapacity(1)
+	assert.Equal(test, []TransitionI{tl}, loop.EnabledTransitions())
+}
+
+func TestCapacityRunningNet(test *testing.T) {
+	disableLogger()
+	net, p, _, r := buildCapacityPipeline(1)
+	r.SetAlertFunc(func(pi PlaceI) bool {
+		return pi.Tokens() == 5
+	})
+
+	net.Start()
+	p.AddTokens(5)
+	r.WaitForAlert() // T is notified every time U drains Q
+	net.Stop()
+	assert.Equal(test, 0, p.Tokens())
+}
+
+func TestCapacityAnalysis(test *testing.T) {
+	/* build net:
+
+	[Gen]──►(Q)──►[Use]
+	       cap 2
+
+	*/
+	net := NewNet("TestNet")
+	q := net.NewPlace("Q")
+	gen := net.NewTransition("Gen")
+	use := net.NewTransition("Use")
+	gen.ConnectTo(q, 1)
+	q.ConnectTo(use, 1)
+	q.SetCapacity(2)
+
+	bounds, err := net.Bounds()
+	assert.NoError(test, err)
+	assert.Equal(test, 2, bounds["Q"])
+	g, err := net.ReachabilityGraph(0)
+	assert.NoError(test, err)
+	assert.Equal(test, 3, len(g.Nodes))
+	ss, err := net.SymbolicReachability(3)
+	assert.NoError(test, err)
+	assert.Equal(test, int64(3), ss.Count().Int64())
+	_, err = net.Unfold(0)
+	assert.Error(test, err)
+
+	// only one producer fits in a step
+	net, p, _, _ := buildCapacityPipeline(1)
+	t2 := net.NewTransition("T2")
+	p.ConnectTo(t2, 1)
+	t2.ConnectTo(net.places[1], 1)
+	p.AddTokens(2)
+	assert.Equal(test, 1, len(net.MaxStep()))
+	assert.Equal(test, 1, net.places[1].Tokens())
+}
@@ -0,0 +1,100 @@
+package petrinet
+
+import "strings"
+
+/*
+Structural classes of the net, computed on weighted Arcs (EnableArcs are
+reported apart). Results of most analysis algorithms (e.g. Commoner's
+theorem) hold only for P/T nets, i.e. nets without inhibitor and read arcs.
+*/
+type Classification struct {
+	StateMachine       bool // ordinary, every transition has exactly one input and one output place
+	MarkedGraph        bool // ordinary, every place has exactly one input and one output transition
+	FreeChoice         bool // ordinary, a place shared by more transitions is their only input place
+	ExtendedFreeChoice bool // ordinary, places sharing an output transition have the same output transitions
+	AsymmetricChoice   bool // ordinary, places sharing an output transition have nested output transitions
+	Pure               bool // no self-loops
+	Ordinary           bool // every Arc has weight 1
+	SelfLoops          bool // some place is both input and output of the same transition
+	InhibitorArcs      bool // some EnableArc has an upper bound (e.g. InhibitedBy)
+	ReadArcs           bool // some EnableArc has a lower bound only
+}
+
+func (c *Classification) String() string {
+	classes := []string{}
+	add := func(is bool, name string) {
+		if is {
+			classes = append(classes, name)
+		}
+	}
+	add(c.StateMachine, "state machine")
+	add(c.MarkedGraph, "marked graph")
+	add(c.FreeChoice, "free-choice")
+	add(c.ExtendedFreeChoice, "extended free-choice")
+	add(c.AsymmetricChoice, "asymmetric-choice")
+	add(c.Pure, "pure")
+	add(c.Ordinary, "ordinary")
+	add(c.SelfLoops, "self-loops")
+	add(c.InhibitorArcs, "inhibitor arcs")
+	add(c.ReadArcs, "read arcs")
+	return "[" + strings.Join(classes, ", ") + "]"
+}
+
+func (n *Net) Classify() *Classification {
+	st := n.structure()
+	c := &Classification{
+		StateMachine:       st.ordinary,
+		MarkedGraph:        st.ordinary,
+		FreeChoice:         st.isFreeChoice(),
+		ExtendedFreeChoice: st.ordinary,
+		AsymmetricChoice:   st.ordinary,
+		Ordinary:           st.ordinary,
+	}
+	for t := range st.transPre {
+		if len(st.transPre[t]) != 1 || len(st.transPost[t]) != 1 {
+			c.StateMachine = false
+		}
+		for _, p := range st.transPre[t] {
+			if contains(st.transPost[t], p) {
+				c.SelfLoops = true
+			}
+		}
+	}
+	c.Pure = !c.SelfLoops
+	for p := range st.placePre {
+		if len(st.placePre[p]) != 1 || len(st.placePost[p]) != 1 {
+			c.MarkedGraph = false
+		}
+		// p• are sorted: transitions are visited in order building the structure
+		for q := p + 1; q < len(st.placePost); q++ {
+			post1, post2 := st.placePost[p], st.placePost[q]
+			if !sharesElement(post1, post2) {
+				continue
+			}
+			sub1, sub2 := isSubset(post1, post2), isSubset(post2, post1)
+			if !sub1 || !sub2 {
+				c.ExtendedFreeChoice = false
+			}
+			if !sub1 && !sub2 {
+				c.AsymmetricChoice = false
+			}
+		}
+	}
+	for _, e := range st.enableArcs {
+		if e.high != undef {
+			c.InhibitorArcs = true
+		} else if e.low != undef {
+			c.ReadArcs = true
+		}
+	}
+	return c
+}
+
+func sharesElement(s1, s2 []int) bool {
+	for _, x := range s1 {
+		if contains(s2, x) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassify(test *testing.T) {
+	// (P1)──►[T1]──►(P2)──►[T2]──►(P1)
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p1, 1)
+	assert.Equal(test, &Classification{
+		StateMachine:       true,
+		MarkedGraph:        true,
+		FreeChoice:         true,
+		ExtendedFreeChoice: true,
+		AsymmetricChoice:   true,
+		Pure:               true,
+		Ordinary:           true,
+	}, net.Classify())
+
+	/* add:
+
+	(P3)──►[T3]──►(P3)
+	  │      ▲
+	  │     (P1)
+	  └────►[T1]
+
+	*/
+	p3 := net.NewPlace("P3")
+	t3 := net.NewTransition("T3")
+	p3.ConnectTo(t1, 1)
+	p3.ConnectTo(t3, 1)
+	p1.ConnectTo(t3, 1)
+	t3.ConnectTo(p3, 1)
+	c := net.Classify()
+	assert.False(test, c.StateMachine)
+	assert.False(test, c.MarkedGraph)
+	assert.False(test, c.FreeChoice)
+	assert.True(test, c.ExtendedFreeChoice)
+	assert.True(test, c.SelfLoops)
+	assert.False(test, c.Pure)
+
+	// weights and enable arcs
+	t2.EnabledBy(p3, t2.SetLow(1))
+	t2.InhibitedBy(p2)
+	t2.ConnectTo(p2, 2)
+	c = net.Classify()
+	assert.False(test, c.Ordinary)
+	assert.False(test, c.ExtendedFreeChoice)
+	assert.True(test, c.ReadArcs)
+	assert.True(test, c.InhibitorArcs)
+	assert.Equal(test, "[self-loops, inhibitor arcs, read arcs]", c.String())
+}
+
+func TestClassifyAsymmetricChoice(test *testing.T) {
+	/* build net:
+
+	(P1)──►[T1]
+	  │      ▲
+	  └─►[T2]│
+	         │
+	(P2)─────┘
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p1.ConnectTo(t1, 1)
+	p1.ConnectTo(t2, 1)
+	p2.ConnectTo(t1, 1)
+	c := net.Classify()
+	assert.False(test, c.ExtendedFreeChoice)
+	assert.True(test, c.AsymmetricChoice)
+}
@@ -0,0 +1,330 @@
+package petrinet
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+/*
+Colored Petri nets: places hold multisets of typed values (colors) instead of
+anonymous tokens. Input arcs either bind a token to a variable or consume the
+multiset computed by an expression over bound variables, guards filter the
+resulting bindings and output arcs compute the tokens produced from them.
+Colored nets are fired synchronously (see 'Step()' and 'Fire()').
+*/
+type ColoredNet struct {
+	id          string
+	mu          sync.Mutex // protects tokens of every place
+	places      []coloredPlace
+	transitions []*ColoredTransition
+	chooser     ColoredChooser // policy choosing transition and binding to fire (nil: first)
+}
+
+// Transition together with a binding enabling it
+type ColoredMode struct {
+	Transition *ColoredTransition
+	Binding    Binding
+}
+
+// Policy picking the transition and binding to fire among the enabled ones
+type ColoredChooser interface {
+	// 'modes' is never empty and follows net order, then bindings order (see 'Bindings()')
+	Choose(n *ColoredNet, modes []ColoredMode) ColoredMode
+}
+
+// Choose uniformly at random, with a reproducible sequence for a given seed
+type coloredRandomChooser struct {
+	rnd *rand.Rand
+}
+
+func NewColoredRandomChooser(seed int64) ColoredChooser {
+	return &coloredRandomChooser{rnd: rand.New(rand.NewSource(seed))}
+}
+func (c *coloredRandomChooser) Choose(n *ColoredNet, modes []ColoredMode) ColoredMode {
+	return modes[c.rnd.Intn(len(modes))]
+}
+
+// Values bound to variables of input arcs
+type Binding map[string]interface{}
+
+// Value bound to variable 'v'
+func Bound[T any](b Binding, v string) T {
+	return b[v].(T)
+}
+
+// Untyped view of places, used by transitions
+type coloredPlace interface {
+	Id() string
+	distinct() []interface{}
+	count(v interface{}) int
+	remove(v interface{})
+	add(v interface{})
+}
+
+type ColoredPlace[T comparable] struct {
+	id   string
+	net  *ColoredNet
+	toks *Multiset[T]
+}
+
+type ColoredTransition struct {
+	id     string
+	net    *ColoredNet
+	in     []*coloredArcIn
+	inExpr []*coloredExprArc
+	out    []*coloredExprArc
+	guard  func(Binding) bool
+}
+
+type coloredArcIn struct {
+	place    coloredPlace
+	variable string
+}
+
+// Arc inscribed with a multiset expression over the binding
+type coloredExprArc struct {
+	place coloredPlace
+	expr  func(Binding) []interface{}
+}
+
+func NewColoredNet(id string) *ColoredNet {
+	return &ColoredNet{id: id}
+}
+
+// New place holding values of type T
+func NewColoredPlace[T comparable](n *ColoredNet, id string) *ColoredPlace[T] {
+	p := &ColoredPlace[T]{id: id, net: n, toks: NewMultiset[T]()}
+	n.places = append(n.places, p)
+	return p
+}
+
+func (n *ColoredNet) NewTransition(id string) *ColoredTransition {
+	t := &ColoredTransition{id: id, net: n}
+	n.transitions = append(n.transitions, t)
+	return t
+}
+
+func (p *ColoredPlace[T]) Id() string {
+	return p.id
+}
+func (p *ColoredPlace[T]) String() string {
+	return fmt.Sprintf("%s: %v", p.id, p.Tokens())
+}
+func (p *ColoredPlace[T]) AddTokens(values ...T) {
+	p.net.mu.Lock()
+	defer p.net.mu.Unlock()
+	for _, v := range values {
+		p.toks.Add(v, 1)
+	}
+}
+
+// Copy of values in place
+func (p *ColoredPlace[T]) Tokens() *Multiset[T] {
+	p.net.mu.Lock()
+	defer p.net.mu.Unlock()
+	return p.toks.clone()
+}
+
+// Input arc binding one token of place to 'variable'. Arcs of the same
+// transition sharing a variable must bind equal values.
+func (p *ColoredPlace[T]) ConnectTo(t *ColoredTransition, variable string) {
+	t.in = append(t.in, &coloredArcIn{place: p, variable: variable})
+}
+
+// Input arc removing from place the values computed by 'expr' from the
+// binding (a value repeated k times needs k tokens). 'expr' can only read
+// variables bound by 'ConnectTo()' arcs of the same transition.
+func (p *ColoredPlace[T]) ConnectToExpr(t *ColoredTransition, expr func(Binding) []T) {
+	t.inExpr = append(t.inExpr, &coloredExprArc{place: p, expr: untyped(expr)})
+}
+
+// Output arc adding to place the values computed by 'expr' from the binding
+func (p *ColoredPlace[T]) ConnectFrom(t *ColoredTransition, expr func(Binding) []T) {
+	t.out = append(t.out, &coloredExprArc{place: p, expr: untyped(expr)})
+}
+
+func untyped[T any](expr func(Binding) []T) func(Binding) []interface{} {
+	return func(b Binding) []interface{} {
+		values := []interface{}{}
+		for _, v := range expr(b) {
+			values = append(values, v)
+		}
+		return values
+	}
+}
+
+func (p *ColoredPlace[T]) distinct() []interface{} {
+	values := []interface{}{}
+	for _, v := range p.toks.values {
+		values = append(values, v)
+	}
+	return values
+}
+func (p *ColoredPlace[T]) count(v interface{}) int {
+	if value, ok := v.(T); ok {
+		return p.toks.Count(value)
+	}
+	return 0
+}
+func (p *ColoredPlace[T]) remove(v interface{}) {
+	p.toks.Remove(v.(T), 1)
+}
+func (p *ColoredPlace[T]) add(v interface{}) {
+	p.toks.Add(v.(T), 1)
+}
+
+func (t *ColoredTransition) Id() string {
+	return t.id
+}
+func (t *ColoredTransition) String() string {
+	return t.id
+}
+
+// Only bindings satisfying 'guard' enable transition
+func (t *ColoredTransition) SetGuard(guard func(Binding) bool) {
+	t.guard = guard
+}
+
+// Bindings enabling transition in current marking (following order of input
+// arcs and of values in places)
+func (n *ColoredNet) Bindings(t *ColoredTransition) []Binding {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.bindings(t)
+}
+
+// Fire transition with given binding
+func (n *ColoredNet) Fire(t *ColoredTransition, b Binding) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if !n.enabledBy(t, b) {
+		return fmt.Errorf("Fire() failed for [%s]: binding %v does not enable [%s]", n.id, b, t.id)
+	}
+	n.fireLocked(t, b)
+	return NoError
+}
+
+// Set policy choosing the transition and binding fired by 'Step()'.
+// Without a policy the first enabled transition fires with its first binding.
+func (n *ColoredNet) SetChooser(c ColoredChooser) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.chooser = c
+}
+
+// Fire one enabled transition with one of its bindings, picked by net policy
+// (see 'SetChooser()').
+// Returns the transition fired and its binding, or nil if no transition is enabled.
+func (n *ColoredNet) Step() (*ColoredTransition, Binding) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	modes := []ColoredMode{}
+	for _, t := range n.transitions {
+		for _, b := range n.bindings(t) {
+			modes = append(modes, ColoredMode{t, b})
+		}
+	}
+	if len(modes) == 0 {
+		return nil, nil
+	}
+	m := modes[0]
+	if n.chooser != nil {
+		m = n.chooser.Choose(n, modes)
+	}
+	n.fireLocked(m.Transition, m.Binding)
+	return m.Transition, m.Binding
+}
+
+func (n *ColoredNet) bindings(t *ColoredTransition) []Binding {
+	found := []Binding{}
+	taken := map[coloredPlace]map[interface{}]int{} // values already bound, per place
+	var bind func(i int, b Binding)
+	bind = func(i int, b Binding) {
+		if i == len(t.in) {
+			if (t.guard == nil || t.guard(b)) && t.exprEnabled(b, taken) {
+				found = append(found, b.clone())
+			}
+			return
+		}
+		arc := t.in[i]
+		if taken[arc.place] == nil {
+			taken[arc.place] = map[interface{}]int{}
+		}
+		for _, v := range arc.place.distinct() {
+			bound, isBound := b[arc.variable]
+			if arc.place.count(v) <= taken[arc.place][v] || (isBound && bound != v) {
+				continue
+			}
+			b[arc.variable] = v
+			taken[arc.place][v]++
+			bind(i+1, b)
+			taken[arc.place][v]--
+			if !isBound {
+				delete(b, arc.variable)
+			}
+		}
+	}
+	bind(0, Binding{})
+	return found
+}
+
+func (n *ColoredNet) enabledBy(t *ColoredTransition, b Binding) bool {
+	taken := map[coloredPlace]map[interface{}]int{}
+	for _, arc := range t.in {
+		v, isBound := b[arc.variable]
+		if !isBound {
+			return false
+		}
+		if taken[arc.place] == nil {
+			taken[arc.place] = map[interface{}]int{}
+		}
+		if arc.place.count(v) <= taken[arc.place][v] {
+			return false
+		}
+		taken[arc.place][v]++
+	}
+	return (t.guard == nil || t.guard(b)) && t.exprEnabled(b, taken)
+}
+
+// Test if places hold the values consumed by expression arcs, besides the
+// ones 'taken' by variable arcs
+func (t *ColoredTransition) exprEnabled(b Binding, taken map[coloredPlace]map[interface{}]int) bool {
+	need := map[coloredPlace]map[interface{}]int{}
+	for _, arc := range t.inExpr {
+		if need[arc.place] == nil {
+			need[arc.place] = map[interface{}]int{}
+		}
+		for _, v := range arc.expr(b) {
+			need[arc.place][v]++
+			if arc.place.count(v) < taken[arc.place][v]+need[arc.place][v] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (n *ColoredNet) fireLocked(t *ColoredTransition, b Binding) {
+	for _, arc := range t.in {
+		arc.place.remove(b[arc.variable])
+	}
+	for _, arc := range t.inExpr {
+		for _, v := range arc.expr(b) {
+			arc.place.remove(v)
+		}
+	}
+	for _, arc := range t.out {
+		for _, v := range arc.expr(b) {
+			arc.place.add(v)
+		}
+	}
+}
+
+func (b Binding) clone() Binding {
+	c := Binding{}
+	for k, v := range b {
+		c[k] = v
+	}
+	return c
+}
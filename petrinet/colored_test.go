@@ -0,0 +1,193 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type order struct {
+	Id   int
+	Item string
+}
+
+func TestMultiset(test *testing.T) {
+	m := NewMultiset("a", "b", "a")
+	assert.Equal(test, 2, m.Count("a"))
+	assert.Equal(test, 3, m.Size())
+	assert.Equal(test, []string{"a", "b"}, m.Values())
+	assert.Equal(test, "{2`a, 1`b}", m.String())
+
+	assert.False(test, m.Remove("b", 2))
+	assert.True(test, m.Remove("a", 2))
+	assert.Equal(test, 0, m.Count("a"))
+	assert.Equal(test, []string{"b"}, m.Values())
+}
+
+func TestColoredNet(test *testing.T) {
+	/* build net:
+
+	(Orders)──o──►[Ship]──o.Id──►(Shipped)
+	                ▲  [o.Item = s]
+	(Stock)───s─────┘
+
+	*/
+	net := NewColoredNet("TestNet")
+	orders := NewColoredPlace[order](net, "Orders")
+	stock := NewColoredPlace[string](net, "Stock")
+	shipped := NewColoredPlace[int](net, "Shipped")
+	ship := net.NewTransition("Ship")
+	orders.ConnectTo(ship, "o")
+	stock.ConnectTo(ship, "s")
+	shipped.ConnectFrom(ship, func(b Binding) []int {
+		return []int{Bound[order](b, "o").Id}
+	})
+	ship.SetGuard(func(b Binding) bool {
+		return Bound[order](b, "o").Item == Bound[string](b, "s")
+	})
+
+	orders.AddTokens(order{1, "pen"}, order{2, "ink"}, order{3, "pen"})
+	stock.AddTokens("pen", "ink", "ink")
+	assert.Equal(test, []Binding{
+		{"o": order{1, "pen"}, "s": "pen"},
+		{"o": order{2, "ink"}, "s": "ink"},
+		{"o": order{3, "pen"}, "s": "pen"},
+	}, net.Bindings(ship))
+
+	// binding not satisfying guard
+	err := net.Fire(ship, Binding{"o": order{2, "ink"}, "s": "pen"})
+	assert.Error(test, err)
+	err = net.Fire(ship, Binding{"o": order{2, "ink"}, "s": "ink"})
+	assert.NoError(test, err)
+	assert.Equal(test, 1, stock.Tokens().Count("ink"))
+
+	t, b := net.Step()
+	assert.Equal(test, ship, t)
+	assert.Equal(test, Binding{"o": order{1, "pen"}, "s": "pen"}, b)
+	// order 3 waits for pens
+	t, _ = net.Step()
+	assert.Nil(test, t)
+	assert.Equal(test, []int{2, 1}, shipped.Tokens().Values())
+	assert.Equal(test, []order{{3, "pen"}}, orders.Tokens().Values())
+	assert.Equal(test, []string{"ink"}, stock.Tokens().Values())
+}
+
+func TestColoredNetSharedVariable(test *testing.T) {
+	/* build net (requests matched with responses by id):
+
+	(Req)──id──►[Match]──id──►(Done)
+	               ▲
+	(Resp)──id─────┘
+
+	*/
+	net := NewColoredNet("TestNet")
+	req := NewColoredPlace[int](net, "Req")
+	resp := NewColoredPlace[int](net, "Resp")
+	done := NewColoredPlace[int](net, "Done")
+	match := net.NewTransition("Match")
+	req.ConnectTo(match, "id")
+	resp.ConnectTo(match, "id")
+	done.ConnectFrom(match, func(b Binding) []int {
+		return []int{Bound[int](b, "id")}
+	})
+
+	req.AddTokens(1, 2, 3)
+	resp.AddTokens(3, 1)
+	assert.Equal(test, []Binding{{"id": 1}, {"id": 3}}, net.Bindings(match))
+	t, _ := net.Step()
+	for t != nil {
+		t, _ = net.Step()
+	}
+	assert.Equal(test, []int{1, 3}, done.Tokens().Values())
+	assert.Equal(test, []int{2}, req.Tokens().Values())
+	assert.Equal(test, 0, resp.Tokens().Size())
+
+	// two arcs from the same place need two tokens
+	pair := NewColoredNet("TestNet")
+	p := NewColoredPlace[string](pair, "P")
+	join := pair.NewTransition("Join")
+	p.ConnectTo(join, "x")
+	p.ConnectTo(join, "x")
+	p.AddTokens("a", "b", "b")
+	assert.Equal(test, []Binding{{"x": "b"}}, pair.Bindings(join))
+}
+
+func TestColoredNetExpressionArcs(test *testing.T) {
+	/* build net (a request for n coins takes n coins):
+
+	(Req)───n───►[Pay]──n──►(Paid)
+	               ▲
+	(Coins)──n`c───┘
+
+	*/
+	net := NewColoredNet("TestNet")
+	req := NewColoredPlace[int](net, "Req")
+	coins := NewColoredPlace[string](net, "Coins")
+	paid := NewColoredPlace[int](net, "Paid")
+	pay := net.NewTransition("Pay")
+	req.ConnectTo(pay, "n")
+	coins.ConnectToExpr(pay, func(b Binding) []string {
+		c := []string{}
+		for k := 0; k < Bound[int](b, "n"); k++ {
+			c = append(c, "c")
+		}
+		return c
+	})
+	paid.ConnectFrom(pay, func(b Binding) []int {
+		return []int{Bound[int](b, "n")}
+	})
+
+	req.AddTokens(5, 2)
+	coins.AddTokens("c", "c", "c")
+	assert.Equal(test, []Binding{{"n": 2}}, net.Bindings(pay))
+	assert.Error(test, net.Fire(pay, Binding{"n": 5}))
+	t, b := net.Step()
+	assert.Equal(test, pay, t)
+	assert.Equal(test, Binding{"n": 2}, b)
+	assert.Equal(test, 1, coins.Tokens().Count("c"))
+	t, _ = net.Step()
+	assert.Nil(test, t)
+}
+
+// Choose the last mode
+type lastModeChooser struct{}
+
+func (c *lastModeChooser) Choose(n *ColoredNet, modes []ColoredMode) ColoredMode {
+	return modes[len(modes)-1]
+}
+
+// (P)──x──►[T]──x──►(Q)  with P holding 1..n
+func buildColoredCopy(n int) (*ColoredNet, *ColoredPlace[int]) {
+	net := NewColoredNet("TestNet")
+	p := NewColoredPlace[int](net, "P")
+	q := NewColoredPlace[int](net, "Q")
+	t := net.NewTransition("T")
+	p.ConnectTo(t, "x")
+	q.ConnectFrom(t, func(b Binding) []int {
+		return []int{Bound[int](b, "x")}
+	})
+	for k := 1; k <= n; k++ {
+		p.AddTokens(k)
+	}
+	return net, q
+}
+
+func TestColoredNetChooser(test *testing.T) {
+	net, _ := buildColoredCopy(3)
+	net.SetChooser(&lastModeChooser{})
+	_, b := net.Step()
+	assert.Equal(test, Binding{"x": 3}, b)
+
+	// same seed, same choices
+	run := func() []int {
+		net, q := buildColoredCopy(10)
+		net.SetChooser(NewColoredRandomChooser(4))
+		for t, _ := net.Step(); t != nil; t, _ = net.Step() {
+		}
+		return q.Tokens().Values()
+	}
+	order := run()
+	assert.Equal(test, 10, len(order))
+	assert.NotEqual(test, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, order)
+	assert.Equal(test, order, run())
+}
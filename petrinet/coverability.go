@@ -0,0 +1,184 @@
+package petrinet
+
+import (
+	"fmt"
+	"math"
+)
+
+// ω: arbitrarily large number of tokens, used in coverability markings
+const Omega = math.MaxInt
+
+/*
+Coverability tree (Karp–Miller) of the net starting from its current marking.
+Markings may contain Omega: a Place with Omega tokens can hold an arbitrarily
+large number of tokens, so it is unbounded.
+
+EnableArc handling:
+  - Omega satisfies any lower bound (SetLow), so it never disables an arc
+    with lower bound only;
+  - Omega exceeds any upper bound (SetHigh), so an arc with upper bound
+    (e.g. InhibitedBy) is disabled by a Place holding Omega tokens;
+  - adding tokens can disable an arc with upper bound, so the net is no more
+    monotone on its Place. That's why such places are never accelerated to
+    Omega: the tree accelerates a marking only if it strictly covers one of
+    its ancestors and it's equal to it on every place read with an upper
+    bound. If one of these places is unbounded the construction never ends
+    and is stopped by 'limit'.
+
+Places with a capacity (see 'SetCapacity()') behave the same way: they
+disable their input transitions when full, so they are never accelerated.
+Guards (see 'SetGuard()') can read any place connected to their transition
+in any way, so these places are never accelerated either: a guard is only
+evaluated on finite markings.
+*/
+type CoverabilityTree struct {
+	Nodes  []*CoverabilityNode
+	net    *Net
+	states []state // node id -> state
+	lo, hi []state // node id -> min and max tokens of every place on the path from root
+}
+
+type CoverabilityNode struct {
+	Id         int
+	Marking    Marking // may contain Omega
+	Parent     int     // parent node id (-1 for root)
+	Transition string  // transition fired from parent ("" for root)
+	Children   []int
+	Duplicate  bool // marking already expanded by another node of the tree
+}
+
+// Build coverability tree from the current marking.
+// Construction is bounded by 'limit' nodes (see package doc).
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) CoverabilityTree(limit int) (*CoverabilityTree, error) {
+	c := &CoverabilityTree{net: n}
+	bounded := n.upperBoundedPlaces()
+	c.addNode(n.currentState(), -1, "")
+	expanded := map[string]bool{}
+
+	for next := 0; next < len(c.states); next++ {
+		s := c.states[next]
+		if expanded[s.key()] {
+			c.Nodes[next].Duplicate = true
+			continue
+		}
+		expanded[s.key()] = true
+		for _, ti := range n.transitions {
+			t := ti.(*Transition)
+			if !n.isEnabledAt(t, s) {
+				continue
+			}
+			if limit > 0 && len(c.states) >= limit {
+				return c, fmt.Errorf("CoverabilityTree() for [%s] stopped after %d nodes", n.id, limit)
+			}
+			succ := c.accelerate(next, n.fireAt(t, s), bounded)
+			c.addNode(succ, next, t.Id())
+		}
+	}
+	return c, NoError
+}
+
+// Places read by an EnableArc with upper bound, with a capacity or connected
+// to a guarded transition
+func (n *Net) upperBoundedPlaces() map[int]bool {
+	bounded := map[int]bool{}
+	for i, p := range n.places {
+		if p.Capacity() != undef {
+			bounded[i] = true
+		}
+	}
+	for _, ti := range n.transitions {
+		if t := ti.(*Transition); t.guard != nil {
+			for _, arc := range t.arcs_in {
+				bounded[n.placeIdx[arc.Place()]] = true
+			}
+			for _, arc := range t.arcs_out {
+				bounded[n.placeIdx[arc.Place()]] = true
+			}
+		}
+		for _, arc := range ti.(*Transition).arcs_in {
+			if e, ok := arc.(*EnableArc); ok && e.high != undef {
+				bounded[n.placeIdx[e.Place()]] = true
+			}
+		}
+	}
+	return bounded
+}
+
+// Replace with Omega tokens of places growing along a path from an ancestor
+func (c *CoverabilityTree) accelerate(parent int, s state, bounded map[int]bool) state {
+	for a := parent; a >= 0 && c.mayCover(a, s, bounded); a = c.Nodes[a].Parent {
+		anc := c.states[a]
+		if !covers(s, anc, bounded) {
+			continue
+		}
+		for i := range s {
+			if anc[i] < s[i] {
+				s[i] = Omega
+			}
+		}
+	}
+	return s
+}
+
+// Test if 's' could cover 'a' or one of its ancestors, given the range of
+// tokens on their path: if not, the walk towards the root can stop
+func (c *CoverabilityTree) mayCover(a int, s state, bounded map[int]bool) bool {
+	for i := range s {
+		if c.lo[a][i] > s[i] || (bounded[i] && c.hi[a][i] < s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Test if 's' covers 'anc' and they are equal on 'bounded' places
+func covers(s, anc state, bounded map[int]bool) bool {
+	for i := range s {
+		if anc[i] > s[i] || (bounded[i] && anc[i] != s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *CoverabilityTree) addNode(s state, parent int, transition string) {
+	id := len(c.states)
+	c.states = append(c.states, s)
+	lo, hi := s.clone(), s.clone()
+	if parent >= 0 {
+		for i := range s {
+			if c.lo[parent][i] < lo[i] {
+				lo[i] = c.lo[parent][i]
+			}
+			if c.hi[parent][i] > hi[i] {
+				hi[i] = c.hi[parent][i]
+			}
+		}
+	}
+	c.lo = append(c.lo, lo)
+	c.hi = append(c.hi, hi)
+	c.Nodes = append(c.Nodes, &CoverabilityNode{
+		Id:         id,
+		Marking:    c.net.toMarking(s),
+		Parent:     parent,
+		Transition: transition,
+	})
+	if parent >= 0 {
+		c.Nodes[parent].Children = append(c.Nodes[parent].Children, id)
+	}
+}
+
+// Ids of places that can hold an arbitrarily large number of tokens
+func (c *CoverabilityTree) Unbounded() []string {
+	ids := []string{}
+	for i, p := range c.net.places {
+		for _, s := range c.states {
+			if s[i] == Omega {
+				ids = append(ids, p.Id())
+				break
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,55 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverabilityTree(test *testing.T) {
+	/* build net:
+
+	[Gen]──►(P)──2──►[T]──►(Q)     (R)──►[U]──►(S)
+
+	*/
+	net := NewNet("TestNet")
+	gen := net.NewTransition("Gen")
+	p := net.NewPlace("P")
+	q := net.NewPlace("Q")
+	t := net.NewTransition("T")
+	gen.ConnectTo(p, 1)
+	p.ConnectTo(t, 2)
+	t.ConnectTo(q, 1)
+	r := net.NewPlace("R")
+	s := net.NewPlace("S")
+	u := net.NewTransition("U")
+	r.ConnectTo(u, 1)
+	u.ConnectTo(s, 1)
+	r.AddTokens(1)
+
+	c, err := net.CoverabilityTree(0)
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"P", "Q"}, c.Unbounded())
+	assert.Equal(test, Marking{"P": 0, "Q": 0, "R": 1, "S": 0}, c.Nodes[0].Marking)
+}
+
+func TestCoverabilityTreeInhibitor(test *testing.T) {
+	/* build net:
+
+	[Gen]──►(P)──►[T]
+	  ●      │
+	  └─<0>──┘
+
+	*/
+	net := NewNet("TestNet")
+	gen := net.NewTransition("Gen")
+	p := net.NewPlace("P")
+	t := net.NewTransition("T")
+	gen.ConnectTo(p, 1)
+	gen.InhibitedBy(p)
+	p.ConnectTo(t, 1)
+
+	c, err := net.CoverabilityTree(0)
+	assert.NoError(test, err)
+	assert.Empty(test, c.Unbounded())
+}
@@ -0,0 +1,336 @@
+package petrinet
+
+import "fmt"
+
+// Label of the implicit self loop on dead markings: a path reaching a dead
+// marking stays there forever, so every path in the state space is infinite.
+const Stutter = "(stutter)"
+
+/*
+Result of a model checking run.
+When available, a firing sequence from the initial marking proves the
+result: a witness if the property holds, a counterexample if it fails.
+Infinite paths are lasso-shaped: 'Prefix' is followed by 'Cycle' repeated forever.
+*/
+type CheckResult struct {
+	Holds  bool
+	Prefix []string // transition ids
+	Cycle  []string // transition ids, empty for finite paths
+}
+
+// Model checker working on a complete reachability graph
+type modelChecker struct {
+	net    *Net
+	g      *ReachabilityGraph
+	succ   [][]int    // successors of every node (dead nodes loop on themselves)
+	labels [][]string // transition id of every successor edge
+	pred   [][]int    // predecessors, one for every edge
+}
+
+func (n *Net) newModelChecker(options ...func(*ExploreOptions)) (*modelChecker, error) {
+	g, err := n.ReachabilityGraph(AnalysisLimit, options...)
+	if err != nil {
+		return nil, err
+	}
+	mc := &modelChecker{
+		net:    n,
+		g:      g,
+		succ:   make([][]int, len(g.Nodes)),
+		labels: make([][]string, len(g.Nodes)),
+		pred:   make([][]int, len(g.Nodes)),
+	}
+	for _, node := range g.Nodes {
+		if len(node.Out) == 0 {
+			mc.addEdge(node.Id, node.Id, Stutter)
+		}
+		for _, e := range node.Out {
+			mc.addEdge(e.From, e.To, e.Transition)
+		}
+	}
+	return mc, NoError
+}
+
+func (mc *modelChecker) addEdge(from, to int, label string) {
+	mc.succ[from] = append(mc.succ[from], to)
+	mc.labels[from] = append(mc.labels[from], label)
+	mc.pred[to] = append(mc.pred[to], from)
+}
+
+// Check CTL property (see 'formula' for syntax) on the initial marking.
+// Atomic propositions use the same semantic of Arc.IsEnabled and
+// EnableArc.IsEnabled. State space must be finite (at most 'AnalysisLimit' markings).
+// Witnesses and counterexamples are given for the outermost temporal operator
+// of the property, e.g. 'EF f' (witness) or 'AG f' (counterexample).
+// 'WithReduction()' is accepted for 'AG f' and 'EF f' properties, with 'f'
+// only reading place tokens.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) CheckCTL(property string, options ...func(*ExploreOptions)) (*CheckResult, error) {
+	f, err := n.parseFormula(property, false)
+	if err != nil {
+		return nil, fmt.Errorf("CheckCTL() failed for [%s]: %v", n.id, err)
+	}
+	opts := ExploreOptions{}
+	for _, o := range options {
+		o(&opts)
+	}
+	if opts.Reduction {
+		places := []string{}
+		if (f.op != "AG" && f.op != "EF") || !f.left.readsPlaces(&places) {
+			return nil, fmt.Errorf("CheckCTL() failed for [%s]: reduction only preserves 'AG f' and 'EF f' with 'f' over place tokens", n.id)
+		}
+		options = append(options, func(o *ExploreOptions) {
+			o.Visible = append(append([]string{}, o.Visible...), places...)
+		})
+	}
+	mc, err := n.newModelChecker(options...)
+	if err != nil {
+		return nil, fmt.Errorf("CheckCTL() failed for [%s]: %v", n.id, err)
+	}
+	r := &CheckResult{Holds: mc.sat(f)[0]}
+	r.Prefix, r.Cycle = mc.explain(f, 0, r.Holds)
+	return r, NoError
+}
+
+// Test if formula has no temporal operators and no 'Enabled' atoms,
+// collecting ids of places it reads
+func (f *formula) readsPlaces(places *[]string) bool {
+	switch f.op {
+	case "true", "false":
+		return true
+	case "atom":
+		*places = append(*places, f.id)
+		return true
+	case "!":
+		return f.left.readsPlaces(places)
+	case "&", "|", "->":
+		return f.left.readsPlaces(places) && f.right.readsPlaces(places)
+	}
+	return false
+}
+
+// Nodes satisfying the state formula
+func (mc *modelChecker) sat(f *formula) []bool {
+	switch f.op {
+	case "!":
+		return not(mc.sat(f.left))
+	case "&", "|", "->":
+		a, b := mc.sat(f.left), mc.sat(f.right)
+		res := make([]bool, len(a))
+		for s := range res {
+			switch f.op {
+			case "&":
+				res[s] = a[s] && b[s]
+			case "|":
+				res[s] = a[s] || b[s]
+			default:
+				res[s] = !a[s] || b[s]
+			}
+		}
+		return res
+	case "EX":
+		return mc.ex(mc.sat(f.left))
+	case "AX":
+		return not(mc.ex(not(mc.sat(f.left))))
+	case "EF":
+		return mc.eu(mc.all(true), mc.sat(f.left))
+	case "AF":
+		return mc.au(mc.all(true), mc.sat(f.left))
+	case "EG":
+		return not(mc.au(mc.all(true), not(mc.sat(f.left))))
+	case "AG":
+		return not(mc.eu(mc.all(true), not(mc.sat(f.left))))
+	case "EU":
+		return mc.eu(mc.sat(f.left), mc.sat(f.right))
+	case "AU":
+		return mc.au(mc.sat(f.left), mc.sat(f.right))
+	}
+	// atomic propositions
+	res := make([]bool, len(mc.succ))
+	for s := range res {
+		res[s] = f.eval(mc.net, mc.g.states[s])
+	}
+	return res
+}
+
+func (mc *modelChecker) all(v bool) []bool {
+	res := make([]bool, len(mc.succ))
+	for s := range res {
+		res[s] = v
+	}
+	return res
+}
+
+func not(a []bool) []bool {
+	res := make([]bool, len(a))
+	for s := range a {
+		res[s] = !a[s]
+	}
+	return res
+}
+
+// Nodes with a successor in 'a'
+func (mc *modelChecker) ex(a []bool) []bool {
+	res := make([]bool, len(a))
+	for s, succ := range mc.succ {
+		for _, next := range succ {
+			res[s] = res[s] || a[next]
+		}
+	}
+	return res
+}
+
+// E[a U b]: nodes reaching 'b' along 'a' nodes (backward search)
+func (mc *modelChecker) eu(a, b []bool) []bool {
+	res := make([]bool, len(a))
+	queue := []int{}
+	for s := range b {
+		if b[s] {
+			res[s] = true
+			queue = append(queue, s)
+		}
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for _, p := range mc.pred[s] {
+			if !res[p] && a[p] {
+				res[p] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+	return res
+}
+
+// A[a U b]: 'a' nodes whose every successor edge leads to the result, or 'b' nodes
+func (mc *modelChecker) au(a, b []bool) []bool {
+	res := make([]bool, len(a))
+	missing := make([]int, len(a)) // successor edges not yet in result
+	queue := []int{}
+	for s := range b {
+		missing[s] = len(mc.succ[s])
+		if b[s] {
+			res[s] = true
+			queue = append(queue, s)
+		}
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for _, p := range mc.pred[s] {
+			if res[p] || !a[p] {
+				continue
+			}
+			missing[p]--
+			if missing[p] == 0 {
+				res[p] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+	return res
+}
+
+// Firing sequence proving that formula holds (or fails) in node 's'
+func (mc *modelChecker) explain(f *formula, s int, holds bool) ([]string, []string) {
+	switch {
+	case f.op == "!":
+		return mc.explain(f.left, s, !holds)
+	case f.op == "&" && !holds, f.op == "|" && holds:
+		if mc.sat(f.left)[s] == holds {
+			return mc.explain(f.left, s, holds)
+		}
+		return mc.explain(f.right, s, holds)
+	case f.op == "->" && holds:
+		if !mc.sat(f.left)[s] {
+			return mc.explain(f.left, s, false)
+		}
+		return mc.explain(f.right, s, true)
+	case f.op == "EX" && holds:
+		return mc.step(s, mc.sat(f.left)), nil
+	case f.op == "AX" && !holds:
+		return mc.step(s, not(mc.sat(f.left))), nil
+	case f.op == "EF" && holds:
+		return mc.path(s, mc.all(true), mc.sat(f.left)), nil
+	case f.op == "AG" && !holds:
+		return mc.path(s, mc.all(true), not(mc.sat(f.left))), nil
+	case f.op == "EU" && holds:
+		return mc.path(s, mc.sat(f.left), mc.sat(f.right)), nil
+	case f.op == "EG" && holds:
+		return mc.lasso(s, mc.sat(f))
+	case f.op == "AF" && !holds:
+		return mc.lasso(s, not(mc.au(mc.all(true), mc.sat(f.left))))
+	case f.op == "AU" && !holds:
+		// E[!b U (!a & !b)] or EG !b
+		a, b := mc.sat(f.left), mc.sat(f.right)
+		target := make([]bool, len(a))
+		for i := range target {
+			target[i] = !a[i] && !b[i]
+		}
+		if mc.eu(not(b), target)[s] {
+			return mc.path(s, not(b), target), nil
+		}
+		return mc.lasso(s, not(mc.au(mc.all(true), b)))
+	}
+	return nil, nil
+}
+
+// Single step from 's' to a node in 'target'
+func (mc *modelChecker) step(s int, target []bool) []string {
+	for i, next := range mc.succ[s] {
+		if target[next] {
+			return []string{mc.labels[s][i]}
+		}
+	}
+	return nil
+}
+
+// Shortest path from 's' to a node in 'target' visiting 'through' nodes only
+func (mc *modelChecker) path(s int, through, target []bool) []string {
+	paths := map[int][]string{s: {}}
+	queue := []int{s}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if target[cur] {
+			return paths[cur]
+		}
+		if !through[cur] {
+			continue
+		}
+		for i, next := range mc.succ[cur] {
+			if _, found := paths[next]; !found {
+				path := make([]string, len(paths[cur]), len(paths[cur])+1)
+				copy(path, paths[cur])
+				paths[next] = append(path, mc.labels[cur][i])
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// Infinite path from 's' never leaving 'set' (every node of 'set' must have
+// a successor in 'set'), as prefix and cycle.
+func (mc *modelChecker) lasso(s int, set []bool) ([]string, []string) {
+	position := map[int]int{}
+	labels := []string{}
+	for cur := s; ; {
+		if k, found := position[cur]; found {
+			return labels[:k], labels[k:]
+		}
+		position[cur] = len(labels)
+		next := -1
+		for i, succ := range mc.succ[cur] {
+			if set[succ] {
+				next = succ
+				labels = append(labels, mc.labels[cur][i])
+				break
+			}
+		}
+		if next < 0 {
+			return nil, nil // not an infinite path
+		}
+		cur = next
+	}
+}
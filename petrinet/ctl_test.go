@@ -0,0 +1,109 @@
+package petrinet
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCTL(test *testing.T) {
+	/* build net:
+
+	(P1)──►[T1]──►(Pa)
+	         ▲
+	(P2)─────┘
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	pa := net.NewPlace("Pa")
+	t1 := net.NewTransition("T1")
+	p1.ConnectTo(t1, 1)
+	p2.ConnectTo(t1, 1)
+	t1.ConnectTo(pa, 1)
+	p1.AddTokens(3)
+	p2.AddTokens(2)
+
+	r, err := net.CheckCTL("AG(Pa <= 2)")
+	assert.NoError(test, err)
+	assert.True(test, r.Holds)
+
+	r, err = net.CheckCTL("AG(Pa <= 1)")
+	assert.NoError(test, err)
+	assert.False(test, r.Holds)
+	assert.Equal(test, []string{"T1", "T1"}, r.Prefix)
+
+	r, err = net.CheckCTL("EF(P1 = 1 & P2 = 0)")
+	assert.NoError(test, err)
+	assert.True(test, r.Holds)
+	assert.Equal(test, []string{"T1", "T1"}, r.Prefix)
+
+	r, err = net.CheckCTL("AF !Enabled(T1)")
+	assert.NoError(test, err)
+	assert.True(test, r.Holds)
+
+	r, err = net.CheckCTL("AG EF Enabled(T1)")
+	assert.NoError(test, err)
+	assert.False(test, r.Holds)
+
+	r, err = net.CheckCTL("AF EnabledT1")
+	assert.NoError(test, err)
+	assert.True(test, r.Holds)
+	_, err = net.CheckCTL("AF EnabledT2")
+	assert.EqualError(test, err, "CheckCTL() failed for [TestNet]: unknown transition [T2] in formula")
+	_, err = net.CheckCTL("AF Enabled T1")
+	assert.Error(test, err)
+	assert.Contains(test, fmt.Sprint(err), "expected Enabled(<transition id>)")
+
+	// path ending in dead marking
+	r, err = net.CheckCTL("EG Pa >= 0")
+	assert.NoError(test, err)
+	assert.True(test, r.Holds)
+	assert.Equal(test, []string{"T1", "T1"}, r.Prefix)
+	assert.Equal(test, []string{Stutter}, r.Cycle)
+
+	_, err = net.CheckCTL("AG(P3 < 1)")
+	assert.Error(test, err)
+	_, err = net.CheckCTL("AG(P1 < )")
+	assert.Error(test, err)
+}
+
+func TestCheckCTLCycle(test *testing.T) {
+	/* build net:
+
+	(P1)──►[T1]──►(P2)──►[T2]──►(P1)
+	  │
+	  └───►[T3]──►(P3)
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	t3 := net.NewTransition("T3")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p1, 1)
+	p1.ConnectTo(t3, 1)
+	t3.ConnectTo(p3, 1)
+	p1.AddTokens(1)
+
+	r, err := net.CheckCTL("AF P3 > 0")
+	assert.NoError(test, err)
+	assert.False(test, r.Holds)
+	assert.Empty(test, r.Prefix)
+	assert.Equal(test, []string{"T1", "T2"}, r.Cycle)
+
+	r, err = net.CheckCTL("A[P3 = 0 U P2 > 0]")
+	assert.NoError(test, err)
+	assert.False(test, r.Holds)
+	assert.Equal(test, []string{"T3"}, r.Prefix)
+
+	_, err = net.CheckCTL("AG(P1 + P2 = 1)")
+	assert.Error(test, err)
+}
@@ -0,0 +1,404 @@
+package petrinet
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+Continuous-time Markov chain of a bounded GSPN (see 'StochasticSimulator'):
+states are the tangible markings (no immediate transition enabled) reachable
+under GSPN semantic, where enabled immediate transitions preempt timed ones.
+Vanishing markings are eliminated, moving their rates to the tangible
+markings reached through immediate firings.
+*/
+type CTMC struct {
+	Markings []Marking // tangible markings
+	Initial  []float64 // initial probability of every tangible marking
+	net      *Net
+	states   []state
+	rates    []map[int]float64 // marking -> marking -> rate (self loops excluded)
+	exit     []float64         // total rate leaving every marking
+	fires    []map[int]float64 // marking -> transition index -> mean firings per time unit
+}
+
+type CTMCSolution struct {
+	Probabilities []float64          // stationary probability of every tangible marking
+	MeanTokens    map[string]float64 // expected tokens per place
+	Throughput    map[string]float64 // mean firings per time unit per transition
+	Iterations    int                // iterations needed by solver
+}
+
+type SolverMethod int
+
+const (
+	PowerIteration SolverMethod = iota // iteration on the uniformized chain
+	GaussSeidel                        // needs an irreducible chain
+)
+
+type SolverOptions struct {
+	Method        SolverMethod
+	Tolerance     float64 // on the sum of probability changes between iterations
+	MaxIterations int
+}
+
+// Solve with given method (power iteration by default)
+func WithMethod(m SolverMethod) func(*SolverOptions) {
+	return func(o *SolverOptions) {
+		o.Method = m
+	}
+}
+
+// Stop iterating when probabilities change less than 'tol' (1e-12 by default)
+func WithTolerance(tol float64) func(*SolverOptions) {
+	return func(o *SolverOptions) {
+		o.Tolerance = tol
+	}
+}
+
+// Build Markov chain from the current marking. State space must be finite
+// (at most 'AnalysisLimit' markings) and immediate transitions can't fire forever.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) CTMC() (*CTMC, error) {
+	g, isVanishing, err := n.gspnGraph()
+	if err != nil {
+		return nil, fmt.Errorf("CTMC() failed for [%s]: %v", n.id, err)
+	}
+	index := map[string]int{}
+	for j, t := range n.transitions {
+		index[t.Id()] = j
+	}
+	immediate := make([][]*ReachabilityEdge, len(g.Nodes))
+	timed := make([][]*ReachabilityEdge, len(g.Nodes))
+	for i, node := range g.Nodes {
+		if isVanishing[i] {
+			immediate[i] = node.Out
+		} else {
+			timed[i] = node.Out
+		}
+	}
+
+	c := &CTMC{net: n}
+	tangible := make([]int, len(g.Nodes))
+	vanishing := []int{}
+	for i := range g.Nodes {
+		if isVanishing[i] {
+			tangible[i] = -1
+			vanishing = append(vanishing, i)
+			continue
+		}
+		tangible[i] = len(c.states)
+		c.states = append(c.states, g.states[i])
+		c.Markings = append(c.Markings, g.Nodes[i].Marking)
+	}
+	absorb, count, err := c.eliminate(g, vanishing, immediate, tangible, index)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Initial = make([]float64, len(c.states))
+	if k := tangible[0]; k >= 0 {
+		c.Initial[k] = 1
+	} else {
+		for k, p := range absorb[0] {
+			c.Initial[k] = p
+		}
+	}
+	c.rates = make([]map[int]float64, len(c.states))
+	c.exit = make([]float64, len(c.states))
+	c.fires = make([]map[int]float64, len(c.states))
+	for i := range g.Nodes {
+		k := tangible[i]
+		if k < 0 {
+			continue
+		}
+		c.rates[k] = map[int]float64{}
+		c.fires[k] = map[int]float64{}
+		for _, e := range timed[i] {
+			j := index[e.Transition]
+			r := n.transitions[j].(*Transition).rate
+			c.fires[k][j] += r
+			if to := tangible[e.To]; to >= 0 {
+				if to != k {
+					c.rates[k][to] += r
+				}
+				continue
+			}
+			for to, p := range absorb[e.To] {
+				if to != k {
+					c.rates[k][to] += r * p
+				}
+			}
+			for u, f := range count[e.To] {
+				c.fires[k][u] += r * f
+			}
+		}
+		for _, r := range c.rates[k] {
+			c.exit[k] += r
+		}
+	}
+	return c, NoError
+}
+
+// Reachability graph under GSPN semantic: from a vanishing marking only the
+// immediate transitions with highest priority fire, preempting timed ones.
+// Returns the graph and which of its markings are vanishing.
+func (n *Net) gspnGraph() (*ReachabilityGraph, []bool, error) {
+	g := &ReachabilityGraph{net: n, index: map[string]int{}}
+	g.addNode(n.currentState())
+	vanishing := []bool{}
+	for next := 0; next < len(g.states); next++ {
+		s := g.states[next]
+		immediate, timed := []TransitionI{}, []TransitionI{}
+		for _, ti := range n.transitions {
+			t := ti.(*Transition)
+			if !n.isEnabledAt(t, s) {
+				continue
+			}
+			if err := t.checkRate(); err != nil {
+				return nil, nil, err
+			}
+			if t.rate == Immediate {
+				immediate = append(immediate, t)
+			} else {
+				timed = append(timed, t)
+			}
+		}
+		fired := timed
+		if len(immediate) > 0 {
+			fired = highestPriority(immediate)
+		}
+		vanishing = append(vanishing, len(immediate) > 0)
+		for _, t := range fired {
+			succ := n.fireAt(t.(*Transition), s)
+			to, found := g.index[succ.key()]
+			if !found {
+				if len(g.states) >= AnalysisLimit {
+					return nil, nil, fmt.Errorf("exploration stopped after %d markings", AnalysisLimit)
+				}
+				to = g.addNode(succ)
+			}
+			g.addEdge(next, to, t.Id())
+		}
+	}
+	return g, vanishing, NoError
+}
+
+// For every vanishing marking compute the probability of reaching each
+// tangible marking and the expected firings of each immediate transition on
+// the way (iterating until values stabilize).
+func (c *CTMC) eliminate(g *ReachabilityGraph, vanishing []int, immediate [][]*ReachabilityEdge, tangible []int, index map[string]int) (map[int]map[int]float64, map[int]map[int]float64, error) {
+	// markings that can't leave vanishing ones would never let time advance
+	leaves := make([]bool, len(g.Nodes))
+	for i := range g.Nodes {
+		leaves[i] = tangible[i] >= 0
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, v := range vanishing {
+			for _, e := range immediate[v] {
+				if !leaves[v] && leaves[e.To] {
+					leaves[v], changed = true, true
+				}
+			}
+		}
+	}
+	for _, v := range vanishing {
+		if !leaves[v] {
+			return nil, nil, fmt.Errorf("CTMC() failed for [%s]: immediate transitions fire forever from %v", c.net.id, g.Nodes[v].Marking)
+		}
+	}
+
+	absorb := map[int]map[int]float64{}
+	count := map[int]map[int]float64{}
+	for _, v := range vanishing {
+		absorb[v] = map[int]float64{}
+		count[v] = map[int]float64{}
+	}
+	for iter := 0; len(vanishing) > 0; iter++ {
+		if iter > AnalysisLimit {
+			return nil, nil, fmt.Errorf("CTMC() failed for [%s]: no convergence eliminating vanishing markings", c.net.id)
+		}
+		change := 0.0
+		for _, v := range vanishing {
+			total := 0.0
+			for _, e := range immediate[v] {
+				total += c.net.transitions[index[e.Transition]].(*Transition).weight
+			}
+			a, f := map[int]float64{}, map[int]float64{}
+			for _, e := range immediate[v] {
+				j := index[e.Transition]
+				p := c.net.transitions[j].(*Transition).weight / total
+				f[j] += p
+				if k := tangible[e.To]; k >= 0 {
+					a[k] += p
+					continue
+				}
+				for k, q := range absorb[e.To] {
+					a[k] += p * q
+				}
+				for u, q := range count[e.To] {
+					f[u] += p * q
+				}
+			}
+			change += distance(absorb[v], a) + distance(count[v], f)
+			absorb[v], count[v] = a, f
+		}
+		if change < 1e-12 {
+			break
+		}
+	}
+	return absorb, count, NoError
+}
+
+// Test if every marking is reached from the first one following 'edges'
+func (c *CTMC) reachesAll(edges []map[int]float64) bool {
+	reached := make([]bool, len(c.states))
+	reached[0] = true
+	stack := []int{0}
+	count := 1
+	for len(stack) > 0 {
+		k := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for to := range edges[k] {
+			if !reached[to] {
+				reached[to] = true
+				count++
+				stack = append(stack, to)
+			}
+		}
+	}
+	return count == len(c.states)
+}
+
+// Sum of absolute differences between values of 'a' and 'b'
+func distance(a, b map[int]float64) float64 {
+	d := 0.0
+	for k, x := range a {
+		d += math.Abs(x - b[k])
+	}
+	for k, y := range b {
+		if _, found := a[k]; !found {
+			d += math.Abs(y)
+		}
+	}
+	return d
+}
+
+// Solve for stationary probabilities and derive expected tokens per place
+// and throughput per transition. Power iteration gives the limiting
+// probabilities from the initial marking, also when the chain is not
+// irreducible; Gauss-Seidel returns an error in that case.
+func (c *CTMC) SteadyState(options ...func(*SolverOptions)) (*CTMCSolution, error) {
+	opts := SolverOptions{Method: PowerIteration, Tolerance: 1e-12, MaxIterations: AnalysisLimit}
+	for _, f := range options {
+		f(&opts)
+	}
+	var pi []float64
+	var iterations int
+	var err error
+	switch opts.Method {
+	case PowerIteration:
+		pi, iterations, err = c.powerIteration(opts)
+	case GaussSeidel:
+		pi, iterations, err = c.gaussSeidel(opts)
+	default:
+		err = fmt.Errorf("SteadyState() failed for [%s]: unknown method %d", c.net.id, opts.Method)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &CTMCSolution{Probabilities: pi, MeanTokens: map[string]float64{}, Throughput: map[string]float64{}, Iterations: iterations}
+	for i, p := range c.net.places {
+		s.MeanTokens[p.Id()] = 0
+		for k, st := range c.states {
+			s.MeanTokens[p.Id()] += pi[k] * float64(st[i])
+		}
+	}
+	for _, t := range c.net.transitions {
+		s.Throughput[t.Id()] = 0
+	}
+	for k, fires := range c.fires {
+		for j, f := range fires {
+			s.Throughput[c.net.transitions[j].Id()] += pi[k] * f
+		}
+	}
+	return s, NoError
+}
+
+// Iterate π = π·P, with P = I + Q/Λ the uniformized chain
+func (c *CTMC) powerIteration(opts SolverOptions) ([]float64, int, error) {
+	lambda := 0.0
+	for _, e := range c.exit {
+		lambda = math.Max(lambda, e)
+	}
+	pi := append([]float64{}, c.Initial...)
+	if lambda == 0 {
+		return pi, 0, NoError // only dead markings
+	}
+	lambda *= 1.05 // keep self loops, so that chain is aperiodic
+	for iter := 1; iter <= opts.MaxIterations; iter++ {
+		next := make([]float64, len(pi))
+		for k, p := range pi {
+			next[k] += p * (1 - c.exit[k]/lambda)
+			for to, r := range c.rates[k] {
+				next[to] += p * r / lambda
+			}
+		}
+		change := 0.0
+		for k := range pi {
+			change += math.Abs(next[k] - pi[k])
+		}
+		pi = next
+		if change < opts.Tolerance {
+			return pi, iter, NoError
+		}
+	}
+	return nil, opts.MaxIterations, fmt.Errorf("SteadyState() failed for [%s]: no convergence after %d iterations", c.net.id, opts.MaxIterations)
+}
+
+// Solve π·Q = 0 in place, one marking at a time, normalizing after every sweep.
+// The chain must be irreducible, so that the solution doesn't depend on the
+// initial marking (starting vector is uniform).
+func (c *CTMC) gaussSeidel(opts SolverOptions) ([]float64, int, error) {
+	if len(c.states) == 1 {
+		return []float64{1}, 0, NoError // only exit is a (possibly vanishing) self loop
+	}
+	in := make([]map[int]float64, len(c.states)) // marking -> marking -> rate
+	for k := range in {
+		in[k] = map[int]float64{}
+	}
+	for k, rates := range c.rates {
+		for to, r := range rates {
+			in[to][k] = r
+		}
+	}
+	if !c.reachesAll(c.rates) || !c.reachesAll(in) {
+		return nil, 0, fmt.Errorf("SteadyState() failed for [%s]: chain is not irreducible, use PowerIteration", c.net.id)
+	}
+	pi := make([]float64, len(c.states))
+	for k := range pi {
+		pi[k] = 1 / float64(len(pi))
+	}
+	for iter := 1; iter <= opts.MaxIterations; iter++ {
+		prev := append([]float64{}, pi...)
+		total := 0.0
+		for k := range pi {
+			flow := 0.0
+			for from, r := range in[k] {
+				flow += pi[from] * r
+			}
+			pi[k] = flow / c.exit[k]
+			total += pi[k]
+		}
+		change := 0.0
+		for k := range pi {
+			pi[k] /= total
+			change += math.Abs(pi[k] - prev[k])
+		}
+		if change < opts.Tolerance {
+			return pi, iter, NoError
+		}
+	}
+	return nil, opts.MaxIterations, fmt.Errorf("SteadyState() failed for [%s]: no convergence after %d iterations", c.net.id, opts.MaxIterations)
+}
@@ -0,0 +1,164 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCTMCSteadyState(test *testing.T) {
+	net := buildOnOffNet(1, 3)
+	c, err := net.CTMC()
+	assert.NoError(test, err)
+	assert.Equal(test, []Marking{{"Off": 1, "On": 0}, {"Off": 0, "On": 1}}, c.Markings)
+	assert.Equal(test, []float64{1, 0}, c.Initial)
+
+	for _, method := range []SolverMethod{PowerIteration, GaussSeidel} {
+		s, err := c.SteadyState(WithMethod(method))
+		assert.NoError(test, err)
+		// two states Markov chain: P(On) = 1/(1+3)
+		assert.InDelta(test, 0.75, s.Probabilities[0], 1e-9)
+		assert.InDelta(test, 0.25, s.MeanTokens["On"], 1e-9)
+		assert.InDelta(test, 0.75, s.MeanTokens["Off"], 1e-9)
+		assert.InDelta(test, 0.75, s.Throughput["TOn"], 1e-9)
+		assert.InDelta(test, 0.75, s.Throughput["TOff"], 1e-9)
+	}
+
+	// Monte Carlo estimate agrees with exact solution
+	s, _ := c.SteadyState()
+	e, err := NewStochasticSimulator(net, 1).SteadyState(100, 20000)
+	assert.NoError(test, err)
+	for id, tokens := range s.MeanTokens {
+		assert.InDelta(test, tokens, e.MeanTokens[id], 0.02)
+	}
+	for id, throughput := range s.Throughput {
+		assert.InDelta(test, throughput, e.Throughput[id], 0.03)
+	}
+}
+
+func TestCTMCVanishing(test *testing.T) {
+	/* build net:
+
+	(Idle)──►[T]──►(Choice)──►[A] (weight 3)──►(Idle)
+	         rate 2     │
+	                    └────►[B] (weight 1)──►(Idle)
+
+	*/
+	net := NewNet("TestNet")
+	idle := net.NewPlace("Idle")
+	choice := net.NewPlace("Choice")
+	t := net.NewTransition("T")
+	a := net.NewTransition("A")
+	b := net.NewTransition("B")
+	idle.ConnectTo(t, 1)
+	t.ConnectTo(choice, 1)
+	choice.ConnectTo(a, 1)
+	a.ConnectTo(idle, 1)
+	choice.ConnectTo(b, 1)
+	b.ConnectTo(idle, 1)
+	t.SetRate(2)
+	a.SetRate(Immediate)
+	b.SetRate(Immediate)
+	a.SetWeight(3)
+	idle.AddTokens(1)
+
+	c, err := net.CTMC()
+	assert.NoError(test, err)
+	assert.Equal(test, []Marking{{"Idle": 1, "Choice": 0}}, c.Markings)
+	s, err := c.SteadyState()
+	assert.NoError(test, err)
+	assert.Equal(test, 0.0, s.MeanTokens["Choice"])
+	assert.InDelta(test, 2, s.Throughput["T"], 1e-9)
+	assert.InDelta(test, 1.5, s.Throughput["A"], 1e-9)
+	assert.InDelta(test, 0.5, s.Throughput["B"], 1e-9)
+
+	// vanishing initial marking
+	idle.AddTokens(-1)
+	choice.AddTokens(1)
+	c, err = net.CTMC()
+	assert.NoError(test, err)
+	assert.Equal(test, []float64{1}, c.Initial)
+
+	// immediate transitions firing forever
+	loop := NewNet("TestNet")
+	p := loop.NewPlace("P")
+	tl := loop.NewTransition("T")
+	p.ConnectTo(tl, 1)
+	tl.ConnectTo(p, 1)
+	p.AddTokens(1)
+	_, err = loop.CTMC()
+	assert.Error(test, err) // unset rate
+	tl.SetRate(Immediate)
+	_, err = loop.CTMC()
+	assert.Error(test, err)
+}
+
+func TestCTMCDeadMarking(test *testing.T) {
+	/* build net:
+
+	(P)──►[T]──►(Q)
+	     rate 1
+
+	*/
+	net := NewNet("TestNet")
+	p := net.NewPlace("P")
+	q := net.NewPlace("Q")
+	t := net.NewTransition("T")
+	p.ConnectTo(t, 1)
+	t.ConnectTo(q, 1)
+	t.SetRate(1)
+	p.AddTokens(1)
+
+	c, err := net.CTMC()
+	assert.NoError(test, err)
+	// every token eventually ends in Q
+	s, err := c.SteadyState()
+	assert.NoError(test, err)
+	assert.InDelta(test, 1, s.MeanTokens["Q"], 1e-9)
+	assert.InDelta(test, 0, s.Throughput["T"], 1e-9)
+
+	_, err = c.SteadyState(WithMethod(GaussSeidel))
+	assert.Error(test, err)
+}
+
+func TestCTMCPreemption(test *testing.T) {
+	/* build net:
+
+	       ┌──►[T1]──►(P2)──►[T3]──┐
+	(P1)───┤  immediate    rate 2  │
+	  ▲    └──►[T2]──►(P3)         │
+	  │        rate 1              │
+	  └────────────────────────────┘
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	t3 := net.NewTransition("T3")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	p1.ConnectTo(t2, 1)
+	t2.ConnectTo(p3, 1)
+	p2.ConnectTo(t3, 1)
+	t3.ConnectTo(p1, 1)
+	t1.SetRate(Immediate)
+	t2.SetRate(1)
+	t3.SetRate(2)
+	p1.AddTokens(1)
+
+	c, err := net.CTMC()
+	assert.NoError(test, err)
+	// T2 is always preempted by T1: P3 is never marked
+	assert.Equal(test, []Marking{{"P1": 0, "P2": 1, "P3": 0}}, c.Markings)
+	for _, method := range []SolverMethod{PowerIteration, GaussSeidel} {
+		s, err := c.SteadyState(WithMethod(method))
+		assert.NoError(test, err)
+		assert.Equal(test, []float64{1}, s.Probabilities)
+		assert.InDelta(test, 2, s.Throughput["T3"], 1e-9)
+		assert.InDelta(test, 2, s.Throughput["T1"], 1e-9)
+		assert.InDelta(test, 0, s.Throughput["T2"], 1e-9)
+	}
+}
@@ -0,0 +1,83 @@
+package petrinet
+
+// Reachable marking where no transition is enabled
+type Deadlock struct {
+	Marking  Marking
+	Sequence []string // shortest firing sequence (transition ids) reaching the marking
+}
+
+type DeadlockReport struct {
+	Deadlocks       []Deadlock
+	DeadTransitions []string // transitions that can never fire from the initial marking (empty when Partial or reduced)
+	Partial         bool     // exploration stopped by limit: dead transitions are unknown
+}
+
+// Find every reachable dead marking and every transition that can never fire,
+// exploring the reachability graph from the current marking.
+// Exploration is bounded by 'limit' markings (see package doc): a partial
+// report lists the dead markings found so far, but no dead transition since
+// any of them could fire in the unexplored part.
+// With 'WithReduction()' dead markings are the same, but dead transitions are
+// not reported since the reduced graph doesn't fire every transition.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) Deadlocks(limit int, options ...func(*ExploreOptions)) (*DeadlockReport, error) {
+	opts := ExploreOptions{}
+	for _, f := range options {
+		f(&opts)
+	}
+	g, err := n.ReachabilityGraph(limit, options...)
+	report := &DeadlockReport{Deadlocks: []Deadlock{}, DeadTransitions: []string{}, Partial: err != nil}
+
+	paths := g.shortestPaths()
+	fired := map[string]bool{}
+	for _, e := range g.Edges {
+		fired[e.Transition] = true
+	}
+	for _, node := range g.Nodes {
+		// with a partial graph unexplored nodes have no edges yet
+		if len(node.Out) == 0 && n.isDead(g.states[node.Id]) {
+			report.Deadlocks = append(report.Deadlocks, Deadlock{node.Marking, paths[node.Id]})
+		}
+	}
+	for _, t := range n.transitions {
+		if !fired[t.Id()] && !report.Partial && !opts.Reduction {
+			report.DeadTransitions = append(report.DeadTransitions, t.Id())
+		}
+	}
+	return report, err
+}
+
+// Test if no transition is enabled in state 's'
+func (n *Net) isDead(s state) bool {
+	for _, t := range n.transitions {
+		if n.isEnabledAt(t.(*Transition), s) {
+			return false
+		}
+	}
+	return true
+}
+
+// Shortest firing sequence from initial marking to every node (breadth-first)
+func (g *ReachabilityGraph) shortestPaths() [][]string {
+	paths := make([][]string, len(g.Nodes))
+	paths[0] = []string{}
+	queue := []int{0}
+	for len(queue) > 0 {
+		from := queue[0]
+		queue = queue[1:]
+		for _, e := range g.Nodes[from].Out {
+			if paths[e.To] == nil {
+				path := make([]string, len(paths[from]), len(paths[from])+1)
+				copy(path, paths[from])
+				paths[e.To] = append(path, e.Transition)
+				queue = append(queue, e.To)
+			}
+		}
+	}
+	return paths
+}
+
+// Shortest firing sequence (transition ids) from initial marking to node
+func (g *ReachabilityGraph) ShortestPath(to int) []string {
+	return g.shortestPaths()[to]
+}
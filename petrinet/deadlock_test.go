@@ -0,0 +1,69 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadlocks(test *testing.T) {
+	/* build net:
+
+	(P1)──►[T1]──►(Pa)
+	         ▲
+	(P2)─────┘
+
+	(P3)──►[T2]──►(Pa)
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	pa := net.NewPlace("Pa")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p1.ConnectTo(t1, 1)
+	p2.ConnectTo(t1, 1)
+	t1.ConnectTo(pa, 1)
+	p3.ConnectTo(t2, 1)
+	t2.ConnectTo(pa, 1)
+
+	p1.AddTokens(3)
+	p2.AddTokens(2)
+	report, err := net.Deadlocks(0)
+	assert.NoError(test, err)
+	assert.Equal(test, 1, len(report.Deadlocks))
+	assert.Equal(test, Marking{"P1": 1, "P2": 0, "P3": 0, "Pa": 2}, report.Deadlocks[0].Marking)
+	assert.Equal(test, []string{"T1", "T1"}, report.Deadlocks[0].Sequence)
+	assert.Equal(test, []string{"T2"}, report.DeadTransitions)
+}
+
+func TestDeadlocksPartial(test *testing.T) {
+	/* build net:
+
+	(P0)──►[A]──►(P1)──►[B]──►(P2)──►[C]──►(P3)
+
+	*/
+	net := NewNet("TestNet")
+	p := net.NewPlace("P0")
+	p.AddTokens(1)
+	for k, id := range []string{"A", "B", "C"} {
+		t := net.NewTransition(id)
+		p.ConnectTo(t, 1)
+		p = net.NewPlace("P" + string(rune('1'+k)))
+		t.ConnectTo(p, 1)
+	}
+
+	// C fires beyond the explored markings: it's not reported as dead
+	report, err := net.Deadlocks(2)
+	assert.Error(test, err)
+	assert.True(test, report.Partial)
+	assert.Equal(test, []string{}, report.DeadTransitions)
+
+	report, err = net.Deadlocks(0)
+	assert.NoError(test, err)
+	assert.False(test, report.Partial)
+	assert.Equal(test, []string{}, report.DeadTransitions)
+	assert.Equal(test, []string{"A", "B", "C"}, report.Deadlocks[0].Sequence)
+}
@@ -1,6 +1,7 @@
 package examples
 
 import (
+	"fmt"
 	"petri-net-simulator/petrinet"
 	"testing"
 
@@ -30,6 +31,32 @@ func TestToggleSwitch(test *testing.T) {
 	net.SavePng("00_toggle_switch.png")
 }
 
+func TestToggleSwitchInvariant(test *testing.T) {
+	const PRESSES = 5
+
+	net := petrinet.NewNet("Test toggle switch invariant")
+	pIn, _ := BuildToggleSwitch(net, "")
+	// every press put in 'In' is consumed by 'On' or 'Off': no P-invariant, no T-invariant
+	m := net.IncidenceMatrix()
+	assert.Equal(test, []string{"In", "Out"}, m.Places)
+	assert.Equal(test, []string{"On", "Off"}, m.Transitions)
+	assert.Equal(test, [][]int{{-1, -1}, {1, -1}}, m.C)
+	assert.Empty(test, net.PInvariants())
+	assert.Empty(test, net.TInvariants())
+
+	// ... still, 'Out' holds the parity of the presses consumed so far
+	pIn.AddTokens(PRESSES)
+	g, err := net.ReachabilityGraph(0)
+	assert.NoError(test, err)
+	assert.Equal(test, PRESSES+1, len(g.Nodes))
+	for _, node := range g.Nodes {
+		assert.Equal(test, (PRESSES-node.Marking["In"])%2, node.Marking["Out"])
+	}
+	bounds, err := net.Bounds()
+	assert.NoError(test, err)
+	assert.Equal(test, 1, bounds["Out"])
+}
+
 func TestModuleNCounter(test *testing.T) {
 	const N = 5
 
@@ -51,6 +78,48 @@ func TestModuleNCounter(test *testing.T) {
 	net.SaveAnimationAsGif("01_modulo_N_counter.gif")
 }
 
+func TestModuleNCounterFairness(test *testing.T) {
+	const N = 3
+
+	net := petrinet.NewNet("Test module-N counter fairness")
+	const TOKENS = 2*N + 1
+	pIn, _ := BuildModuloNCounter(net, "", N)
+	pIn.AddTokens(TOKENS)
+
+	// every token added to 'In' is eventually counted
+	r, err := net.CheckLTL(fmt.Sprintf("F G(In = 0 & Cnt = %d)", TOKENS%N))
+	assert.NoError(test, err)
+	assert.True(test, r.Holds)
+	// ... and counter always holds the tokens consumed so far (modulo N)
+	g, err := net.ReachabilityGraph(0)
+	assert.NoError(test, err)
+	for _, node := range g.Nodes {
+		assert.Equal(test, (TOKENS-node.Marking["In"])%N, node.Marking["Cnt"])
+	}
+	// ... and counter never exceeds N-1
+	r, err = net.CheckLTL("G Cnt < " + fmt.Sprint(N))
+	assert.NoError(test, err)
+	assert.True(test, r.Holds)
+}
+
+func TestModuleNCounterMaxStep(test *testing.T) {
+	const N = 3
+
+	// two counters sharing the same clock
+	net := petrinet.NewNet("Test module-N counters in lockstep")
+	pInA, pCntA := BuildModuloNCounter(net, "A", N)
+	pInB, pCntB := BuildModuloNCounter(net, "B", N)
+
+	for i := 1; i <= 2*N; i++ {
+		pInA.AddTokens(1)
+		pInB.AddTokens(1)
+		step := net.MaxStep()
+		assert.Equal(test, 2, len(step))
+		assert.Equal(test, i%N, pCntA.Tokens())
+		assert.Equal(test, i%N, pCntB.Tokens())
+	}
+}
+
 func TestAdder(test *testing.T) {
 	net := petrinet.NewNet("Test Adder")
 	pX := net.NewPlace("X")
@@ -79,6 +148,23 @@ func TestAdder(test *testing.T) {
 	//net.SaveAnimationAsGif("02_adder.gif")
 }
 
+func TestAdderLiveness(test *testing.T) {
+	net := petrinet.NewNet("Test Adder liveness")
+	pX := net.NewPlace("X")
+	pY := net.NewPlace("Y")
+	pRun, _, _ := BuildAdder(net, "", pX, pY)
+	pX.AddTokens(3)
+	pY.AddTokens(2)
+	pRun.AddTokens(1)
+
+	levels, err := net.Liveness()
+	assert.NoError(test, err)
+	// adder runs once: every transition fires, none of them forever
+	assert.Equal(test, petrinet.L1, levels["AddX"])
+	assert.Equal(test, petrinet.L1, levels["AddY"])
+	assert.Equal(test, petrinet.L1, levels["Next"])
+}
+
 func TestAdder3(test *testing.T) {
 	net := petrinet.NewNet("Test 3-Adder")
 	pX := net.NewPlace("X")
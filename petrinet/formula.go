@@ -0,0 +1,326 @@
+package petrinet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+/*
+Temporal logic formulas over net markings.
+
+Atomic propositions:
+  - true, false
+  - <place id> <op> <int>   with <op> one of <, <=, =, ==, !=, >=, >
+  - Enabled(<transition id>), or Enabled<transition id> (e.g. 'AF EnabledT1')
+    when no place has that id
+Boolean operators (by increasing precedence): ->, |, &, !
+CTL operators: EX f, AX f, EF f, AF f, EG f, AG f, E[f U g], A[f U g]
+LTL operators: X f, F f, G f, f U g, f R g (U and R bind less than |)
+
+Place and transition ids can contain letters, digits, '_' and '.', and
+cannot be equal to an operator name.
+*/
+type formula struct {
+	op          string // operator or "atom", "enabled", "true", "false"
+	left, right *formula
+	id          string // atom: place id, enabled: transition id
+	place       int    // atom: place index
+	cmp         string // atom: comparison operator
+	value       int    // atom: compared value
+	transition  *Transition
+}
+
+func (f *formula) String() string {
+	switch f.op {
+	case "true", "false":
+		return f.op
+	case "atom":
+		return fmt.Sprintf("%s %s %d", f.id, f.cmp, f.value)
+	case "enabled":
+		return "Enabled(" + f.id + ")"
+	case "!", "EX", "AX", "EF", "AF", "EG", "AG", "X", "F", "G":
+		return f.op + "(" + f.left.String() + ")"
+	case "EU", "AU":
+		return f.op[:1] + "[" + f.left.String() + " U " + f.right.String() + "]"
+	}
+	return "(" + f.left.String() + " " + f.op + " " + f.right.String() + ")"
+}
+
+// Evaluate a state formula without temporal operators
+func (f *formula) eval(n *Net, s state) bool {
+	switch f.op {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "atom":
+		return compare(s[f.place], f.cmp, f.value)
+	case "enabled":
+		return n.isEnabledAt(f.transition, s)
+	case "!":
+		return !f.left.eval(n, s)
+	case "&":
+		return f.left.eval(n, s) && f.right.eval(n, s)
+	case "|":
+		return f.left.eval(n, s) || f.right.eval(n, s)
+	case "->":
+		return !f.left.eval(n, s) || f.right.eval(n, s)
+	}
+	logger.Panicf("Operator [%s] cannot be evaluated on a single marking", f.op)
+	return false
+}
+
+func compare(toks int, cmp string, value int) bool {
+	switch cmp {
+	case "<":
+		return toks < value
+	case "<=":
+		return toks <= value
+	case "=", "==":
+		return toks == value
+	case "!=":
+		return toks != value
+	case ">=":
+		return toks >= value
+	case ">":
+		return toks > value
+	}
+	return false
+}
+
+type formulaParser struct {
+	net      *Net
+	tokens   []string
+	pos      int
+	ltl      bool            // LTL formula, CTL otherwise
+	keywords map[string]bool // operators of the logic
+}
+
+var ctlKeywords = map[string]bool{
+	"EX": true, "AX": true, "EF": true, "AF": true, "EG": true, "AG": true, "E": true, "A": true, "U": true,
+}
+var ltlKeywords = map[string]bool{
+	"X": true, "F": true, "G": true, "U": true, "R": true,
+}
+
+// Parse CTL (or LTL) formula, resolving place and transition ids on net
+func (n *Net) parseFormula(text string, ltl bool) (*formula, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &formulaParser{net: n, tokens: tokens, ltl: ltl, keywords: ctlKeywords}
+	if ltl {
+		p.keywords = ltlKeywords
+	}
+	f, err := p.implication()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected [%s] in formula [%s]", p.peek(), text)
+	}
+	return f, NoError
+}
+
+func tokenize(text string) ([]string, error) {
+	tokens := []string{}
+	runes := []rune(text)
+	isIdRune := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+	}
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case isIdRune(r):
+			j := i
+			for j < len(runes) && isIdRune(runes[j]) {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j
+		case strings.ContainsRune("()[]&|", r):
+			tokens = append(tokens, string(r))
+			i++
+		case strings.ContainsRune("<>=!-", r):
+			op := string(r)
+			if i+1 < len(runes) {
+				switch two := op + string(runes[i+1]); two {
+				case "<=", ">=", "==", "!=", "->":
+					op = two
+				}
+			}
+			if op == "-" {
+				return nil, fmt.Errorf("unexpected [-] in formula [%s]", text)
+			}
+			tokens = append(tokens, op)
+			i += len(op)
+		default:
+			return nil, fmt.Errorf("unexpected [%c] in formula [%s]", r, text)
+		}
+	}
+	return tokens, NoError
+}
+
+func (p *formulaParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+func (p *formulaParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+func (p *formulaParser) expect(tok string) error {
+	if got := p.next(); got != tok {
+		return fmt.Errorf("expected [%s] in formula, found [%s]", tok, got)
+	}
+	return NoError
+}
+
+func (p *formulaParser) implication() (*formula, error) {
+	left, err := p.until()
+	if err != nil || p.peek() != "->" {
+		return left, err
+	}
+	p.next()
+	right, err := p.implication()
+	if err != nil {
+		return nil, err
+	}
+	return &formula{op: "->", left: left, right: right}, NoError
+}
+
+// LTL binary operators: f U g, f R g (right associative)
+func (p *formulaParser) until() (*formula, error) {
+	left, err := p.or()
+	if err != nil || !p.ltl || (p.peek() != "U" && p.peek() != "R") {
+		return left, err
+	}
+	op := p.next()
+	right, err := p.until()
+	if err != nil {
+		return nil, err
+	}
+	return &formula{op: op, left: left, right: right}, NoError
+}
+
+func (p *formulaParser) or() (*formula, error) {
+	left, err := p.and()
+	for err == nil && p.peek() == "|" {
+		p.next()
+		var right *formula
+		if right, err = p.and(); err == nil {
+			left = &formula{op: "|", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *formulaParser) and() (*formula, error) {
+	left, err := p.unary()
+	for err == nil && p.peek() == "&" {
+		p.next()
+		var right *formula
+		if right, err = p.unary(); err == nil {
+			left = &formula{op: "&", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *formulaParser) unary() (*formula, error) {
+	tok := p.peek()
+	switch {
+	case tok == "!" || (p.keywords[tok] && tok != "E" && tok != "A" && tok != "U" && tok != "R"):
+		p.next()
+		f, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &formula{op: tok, left: f}, NoError
+	case p.keywords[tok] && (tok == "E" || tok == "A"):
+		// E[f U g], A[f U g]
+		p.next()
+		if err := p.expect("["); err != nil {
+			return nil, err
+		}
+		left, err := p.implication()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("U"); err != nil {
+			return nil, err
+		}
+		right, err := p.implication()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		return &formula{op: tok + "U", left: left, right: right}, NoError
+	case tok == "(":
+		p.next()
+		f, err := p.implication()
+		if err != nil {
+			return nil, err
+		}
+		return f, p.expect(")")
+	}
+	return p.atom()
+}
+
+// Enabledness of transition 'id'
+func (p *formulaParser) enabled(id string) (*formula, error) {
+	for _, t := range p.net.transitions {
+		if t.Id() == id {
+			return &formula{op: "enabled", id: id, transition: t.(*Transition)}, NoError
+		}
+	}
+	return nil, fmt.Errorf("unknown transition [%s] in formula", id)
+}
+
+func (p *formulaParser) atom() (*formula, error) {
+	tok := p.next()
+	switch tok {
+	case "true", "false":
+		return &formula{op: tok}, NoError
+	case "Enabled":
+		if err := p.expect("("); err != nil {
+			return nil, fmt.Errorf("%v (expected Enabled(<transition id>))", err)
+		}
+		f, err := p.enabled(p.next())
+		if err != nil {
+			return nil, err
+		}
+		return f, p.expect(")")
+	}
+	place := -1
+	for i, pl := range p.net.places {
+		if pl.Id() == tok {
+			place = i
+		}
+	}
+	if place < 0 && strings.HasPrefix(tok, "Enabled") {
+		return p.enabled(strings.TrimPrefix(tok, "Enabled"))
+	}
+	if place < 0 || p.keywords[tok] {
+		return nil, fmt.Errorf("unknown place [%s] in formula (expected '<place id> <op> <int>', 'Enabled(<transition id>)' or 'Enabled<transition id>')", tok)
+	}
+	cmp := p.next()
+	if !strings.Contains(" < <= = == != >= > ", " "+cmp+" ") {
+		return nil, fmt.Errorf("expected comparison after place [%s] in formula, found [%s]", tok, cmp)
+	}
+	value, err := strconv.Atoi(p.next())
+	if err != nil {
+		return nil, fmt.Errorf("expected integer after [%s %s] in formula", tok, cmp)
+	}
+	return &formula{op: "atom", id: tok, place: place, cmp: cmp, value: value}, NoError
+}
@@ -0,0 +1,86 @@
+package petrinet
+
+import "time"
+
+/*
+Guards and firing actions: a guard is a condition on the marking of the
+places connected to a transition, evaluated together with its arcs (while
+places are locked, for a running net). Actions are invoked after every
+firing, once places are unlocked, so they can call external services without
+blocking the net.
+Analysis algorithms evaluate guards on simulated markings too, so a guard must
+only depend on the marking it receives. Actions only follow real firings.
+*/
+
+// Firing of a transition, as seen by its actions
+type FiringEvent struct {
+	Transition TransitionI
+	Before     Marking       // tokens of connected places before firing
+	After      Marking       // tokens of connected places after firing
+	Time       time.Duration // net clock time of firing
+}
+
+// Enable transition only when 'guard' holds on the marking of connected places
+func (t *Transition) SetGuard(guard func(Marking) bool) {
+	t.guard = guard
+}
+
+// Invoke 'action' after every firing of transition (in registration order)
+func (t *Transition) OnFire(action func(FiringEvent)) {
+	t.actions = append(t.actions, action)
+}
+
+// Marking of places connected to 't' (places must be locked)
+func (t *Transition) localMarking() Marking {
+	m := Marking{}
+	for _, arc := range t.arcs_in {
+		m[arc.Place().Id()] = arc.Place().Tokens()
+	}
+	for _, arc := range t.arcs_out {
+		m[arc.Place().Id()] = arc.Place().Tokens()
+	}
+	return m
+}
+
+// Marking of places connected to 't' in state 's'
+func (n *Net) localMarkingAt(t *Transition, s state) Marking {
+	m := Marking{}
+	for _, arc := range t.arcs_in {
+		m[arc.Place().Id()] = s[n.placeIdx[arc.Place()]]
+	}
+	for _, arc := range t.arcs_out {
+		m[arc.Place().Id()] = s[n.placeIdx[arc.Place()]]
+	}
+	return m
+}
+
+// Test if some transition of the net has a guard
+func (n *Net) hasGuards() bool {
+	for _, t := range n.transitions {
+		if t.(*Transition).guard != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Event of a firing, given connected places marking before it (nil when transition has no actions)
+func (t *Transition) firingEvent(before Marking) *FiringEvent {
+	e := &FiringEvent{Transition: t, Time: t.net.clock.Now()}
+	if before != nil {
+		e.Before, e.After = before, t.localMarking()
+	}
+	return e
+}
+
+// Run actions of fired transitions (places must be unlocked)
+func dispatch(events ...*FiringEvent) {
+	for _, e := range events {
+		if e == nil {
+			continue
+		}
+		for _, action := range e.Transition.(*Transition).actions {
+			action(*e)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// (P)──►[T]──►(Q)──►[U]──►(R)  with T guarded by 'Q = 0'
+func buildGuardedPipeline() (*Net, PlaceI, PlaceI, TransitionI) {
+	net := NewNet("TestNet")
+	p := net.NewPlace("P")
+	q := net.NewPlace("Q")
+	r := net.NewPlace("R")
+	t := net.NewTransition("T")
+	u := net.NewTransition("U")
+	p.ConnectTo(t, 1)
+	t.ConnectTo(q, 1)
+	q.ConnectTo(u, 1)
+	u.ConnectTo(r, 1)
+	t.SetGuard(func(m Marking) bool {
+		return m["Q"] == 0
+	})
+	return net, p, r, t
+}
+
+func TestGuardStep(test *testing.T) {
+	net, p, _, t := buildGuardedPipeline()
+	events := []FiringEvent{}
+	t.OnFire(func(e FiringEvent) {
+		events = append(events, e)
+	})
+	p.AddTokens(2)
+
+	assert.Equal(test, t, net.Step())
+	// guard blocks T until U empties Q
+	assert.Equal(test, []TransitionI{net.transitions[1]}, net.EnabledTransitions())
+	assert.Error(test, net.Fire(t))
+	assert.Equal(test, "U", net.Step().Id())
+	assert.Equal(test, t, net.Step())
+
+	assert.Equal(test, 2, len(events))
+	assert.Equal(test, t, events[0].Transition)
+	assert.Equal(test, Marking{"P": 2, "Q": 0}, events[0].Before)
+	assert.Equal(test, Marking{"P": 1, "Q": 1}, events[0].After)
+}
+
+func TestGuardRunningNet(test *testing.T) {
+	disableLogger()
+	net, p, r, t := buildGuardedPipeline()
+	fired := make(chan FiringEvent, 10)
+	t.OnFire(func(e FiringEvent) {
+		fired <- e
+	})
+	r.SetAlertFunc(func(pi PlaceI) bool {
+		return pi.Tokens() == 3
+	})
+
+	net.Start()
+	p.AddTokens(3)
+	r.WaitForAlert() // T is notified when U empties its output place
+	net.Stop()
+	for k := 3; k > 0; k-- { // actions of T run in firing order
+		e := <-fired
+		assert.Equal(test, k, e.Before["P"])
+		assert.Equal(test, 1, e.After["Q"])
+	}
+}
+
+func TestGuardAnalysis(test *testing.T) {
+	net, p, _, _ := buildGuardedPipeline()
+	p.AddTokens(2)
+
+	g, err := net.ReachabilityGraph(0)
+	assert.NoError(test, err)
+	// Q never holds more than one token
+	assert.Equal(test, 5, len(g.Nodes))
+	bounds, err := net.Bounds()
+	assert.NoError(test, err)
+	assert.Equal(test, 1, bounds["Q"])
+
+	reduced, err := net.ReachabilityGraph(0, WithReduction())
+	assert.NoError(test, err)
+	assert.Equal(test, len(deadlocksOf(g)), len(deadlocksOf(reduced)))
+
+	_, err = net.SymbolicReachability(2)
+	assert.Error(test, err)
+	_, err = net.Unfold(0)
+	assert.Error(test, err)
+
+	// guarded transition and its places are kept
+	red, err := net.Reduce()
+	assert.NoError(test, err)
+	assert.Contains(test, red.Transitions, "T")
+	assert.Contains(test, red.Places, "Q")
+}
+
+// Dead markings of a graph
+func deadlocksOf(g *ReachabilityGraph) []int {
+	dead := []int{}
+	for _, node := range g.Nodes {
+		if len(node.Out) == 0 {
+			dead = append(dead, node.Id)
+		}
+	}
+	return dead
+}
+
+func TestGuardBounds(test *testing.T) {
+	/* build net:
+
+	[Gen]──►(P)   with Gen guarded by 'P < 3'
+
+	*/
+	net := NewNet("TestNet")
+	p := net.NewPlace("P")
+	gen := net.NewTransition("Gen")
+	gen.ConnectTo(p, 1)
+	gen.SetGuard(func(m Marking) bool {
+		return m["P"] < 3
+	})
+
+	g, err := net.ReachabilityGraph(0)
+	assert.NoError(test, err)
+	assert.Equal(test, 4, len(g.Nodes))
+	bounds, err := net.Bounds()
+	assert.NoError(test, err)
+	assert.Equal(test, Marking{"P": 3}, bounds)
+	safe, err := net.IsSafe()
+	assert.NoError(test, err)
+	assert.False(test, safe)
+	kBounded, err := net.IsKBounded(3)
+	assert.NoError(test, err)
+	assert.True(test, kBounded)
+}
@@ -0,0 +1,75 @@
+package petrinet
+
+/*
+Incidence matrix of the net: one row for every Place and one column for every
+Transition, both in creation order.
+Pre[p][t] are tokens consumed by 't' from 'p', Post[p][t] tokens produced
+by 't' into 'p' and C = Post - Pre.
+EnableArc never moves tokens, so it's not represented in the matrix.
+*/
+type IncidenceMatrix struct {
+	Places      []string // row ids
+	Transitions []string // column ids
+	Pre         [][]int
+	Post        [][]int
+	C           [][]int
+}
+
+func (n *Net) IncidenceMatrix() *IncidenceMatrix {
+	m := &IncidenceMatrix{
+		Places:      make([]string, len(n.places)),
+		Transitions: make([]string, len(n.transitions)),
+		Pre:         newIntMatrix(len(n.places), len(n.transitions)),
+		Post:        newIntMatrix(len(n.places), len(n.transitions)),
+		C:           newIntMatrix(len(n.places), len(n.transitions)),
+	}
+	for i, p := range n.places {
+		m.Places[i] = p.Id()
+	}
+	for j, ti := range n.transitions {
+		t := ti.(*Transition)
+		m.Transitions[j] = t.Id()
+		for _, arc := range t.arcs_in {
+			m.Pre[n.placeIdx[arc.Place()]][j] += arcWeight(arc)
+		}
+		for _, arc := range t.arcs_out {
+			m.Post[n.placeIdx[arc.Place()]][j] += arcWeight(arc)
+		}
+	}
+	for i := range m.C {
+		for j := range m.C[i] {
+			m.C[i][j] = m.Post[i][j] - m.Pre[i][j]
+		}
+	}
+	return m
+}
+
+func newIntMatrix(rows, cols int) [][]int {
+	m := make([][]int, rows)
+	for i := range m {
+		m[i] = make([]int, cols)
+	}
+	return m
+}
+
+// State equation check: test if M' = M0 + C·x has a non-negative integer
+// solution 'x', where M0 is the current marking and M' the target marking
+// (places missing in target are considered empty).
+// It's a necessary condition only: false means target is NOT reachable,
+// true means target may be reachable. EnableArcs are ignored, so they can
+// only make the answer less precise, never wrong.
+// If the solver gives up on a hard instance, true is returned.
+func (n *Net) CanPossiblyReach(target Marking) bool {
+	m := n.IncidenceMatrix()
+	m0 := n.currentState()
+	delta := make([]int, len(n.places))
+	for i, p := range n.places {
+		delta[i] = target[p.Id()] - m0[i]
+	}
+	feasible, decided := ilpFeasible(m.C, delta)
+	if !decided {
+		logger.Printf("Net [%s] CanPossiblyReach() undecided, assuming reachable", n.id)
+		return true
+	}
+	return feasible
+}
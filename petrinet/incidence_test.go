@@ -0,0 +1,59 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncidenceMatrix(test *testing.T) {
+	/* build net:
+
+	(P1)──2──►[T1]──►(P2)──►[T2]──►(P1)
+	            ●
+	(P3)──<0>───┘
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p1.ConnectTo(t1, 2)
+	t1.ConnectTo(p2, 1)
+	t1.InhibitedBy(p3)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p1, 1)
+
+	m := net.IncidenceMatrix()
+	assert.Equal(test, []string{"P1", "P2", "P3"}, m.Places)
+	assert.Equal(test, []string{"T1", "T2"}, m.Transitions)
+	assert.Equal(test, [][]int{{2, 0}, {0, 1}, {0, 0}}, m.Pre)
+	assert.Equal(test, [][]int{{0, 1}, {1, 0}, {0, 0}}, m.Post)
+	assert.Equal(test, [][]int{{-2, 1}, {1, -1}, {0, 0}}, m.C)
+}
+
+func TestCanPossiblyReach(test *testing.T) {
+	// (P1)──2──►[T]──3──►(P2)
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	t := net.NewTransition("T")
+	p1.ConnectTo(t, 2)
+	t.ConnectTo(p2, 3)
+	p1.AddTokens(5)
+
+	assert.True(test, net.CanPossiblyReach(Marking{"P1": 5}))
+	assert.True(test, net.CanPossiblyReach(Marking{"P1": 1, "P2": 6}))
+	assert.False(test, net.CanPossiblyReach(Marking{"P1": 3, "P2": 2}))
+	assert.False(test, net.CanPossiblyReach(Marking{"P1": 7}))
+
+	// (P3)──2──►[U]  has rational solution x = 5/2 only
+	p3 := net.NewPlace("P3")
+	u := net.NewTransition("U")
+	p3.ConnectTo(u, 2)
+	p3.AddTokens(5)
+	assert.False(test, net.CanPossiblyReach(Marking{"P1": 5}))
+	assert.True(test, net.CanPossiblyReach(Marking{"P1": 5, "P3": 1}))
+}
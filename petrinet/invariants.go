@@ -0,0 +1,166 @@
+package petrinet
+
+/*
+Semiflow (invariant) of the net: non-negative weight of every Place
+(P-invariant) or Transition (T-invariant). Only ids with weight > 0 are present.
+
+P-invariant 'y' (y·C = 0): weighted sum of tokens y·M is the same in every
+reachable marking M.
+T-invariant 'x' (C·x = 0): firing every transition x[t] times (in any
+feasible order) gives back the initial marking.
+EnableArc never moves tokens, so it's ignored by the computation.
+*/
+type Invariant map[string]int
+
+// Weighted sum of tokens of a P-invariant in marking 'm'
+func (inv Invariant) Weighted(m Marking) int {
+	sum := 0
+	for id, w := range inv {
+		sum += w * m[id]
+	}
+	return sum
+}
+
+// Minimal P-invariants of the net (Farkas algorithm)
+func (n *Net) PInvariants() []Invariant {
+	m := n.IncidenceMatrix()
+	return toInvariants(farkas(m.C), m.Places)
+}
+
+// Minimal T-invariants of the net (Farkas algorithm)
+func (n *Net) TInvariants() []Invariant {
+	m := n.IncidenceMatrix()
+	return toInvariants(farkas(transpose(m.C)), m.Transitions)
+}
+
+func toInvariants(semiflows [][]int, ids []string) []Invariant {
+	invs := []Invariant{}
+	for _, y := range semiflows {
+		inv := Invariant{}
+		for i, w := range y {
+			if w != 0 {
+				inv[ids[i]] = w
+			}
+		}
+		invs = append(invs, inv)
+	}
+	return invs
+}
+
+// Minimal support non-negative integer vectors 'y' such that y·A = 0
+func farkas(A [][]int) [][]int {
+	rows := len(A)
+	cols := 0
+	if rows > 0 {
+		cols = len(A[0])
+	}
+	// D = [ A | I ]
+	D := make([][]int, rows)
+	for i := range A {
+		D[i] = make([]int, cols+rows)
+		copy(D[i], A[i])
+		D[i][cols+i] = 1
+	}
+	for j := 0; j < cols; j++ {
+		next := [][]int{}
+		for _, r := range D {
+			if r[j] == 0 {
+				next = append(next, r)
+			}
+		}
+		// combine rows with opposite signs to cancel column j
+		for a, r1 := range D {
+			for _, r2 := range D[a+1:] {
+				if r1[j]*r2[j] >= 0 {
+					continue
+				}
+				c1, c2 := abs(r2[j]), abs(r1[j])
+				r := make([]int, len(r1))
+				for k := range r {
+					r[k] = c1*r1[k] + c2*r2[k]
+				}
+				next = append(next, normalize(r))
+			}
+		}
+		D = minimalSupports(next, cols)
+	}
+	semiflows := [][]int{}
+	for _, r := range D {
+		semiflows = append(semiflows, r[cols:])
+	}
+	return semiflows
+}
+
+// Remove duplicated rows and rows whose support (from column 'from') contains another row support
+func minimalSupports(D [][]int, from int) [][]int {
+	minimal := [][]int{}
+	for a, r1 := range D {
+		keep := true
+		for b, r2 := range D {
+			if a == b {
+				continue
+			}
+			sub := supportSubset(r2[from:], r1[from:])
+			// on equal supports keep the first row only
+			if sub && (!supportSubset(r1[from:], r2[from:]) || b < a) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			minimal = append(minimal, r1)
+		}
+	}
+	return minimal
+}
+
+// Test if support of 'y1' is contained in support of 'y2'
+func supportSubset(y1, y2 []int) bool {
+	for k := range y1 {
+		if y1[k] != 0 && y2[k] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Divide vector by gcd of its entries
+func normalize(r []int) []int {
+	g := 0
+	for _, v := range r {
+		g = gcd(g, abs(v))
+	}
+	if g > 1 {
+		for k := range r {
+			r[k] /= g
+		}
+	}
+	return r
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func transpose(A [][]int) [][]int {
+	if len(A) == 0 {
+		return [][]int{}
+	}
+	T := newIntMatrix(len(A[0]), len(A))
+	for i := range A {
+		for j := range A[i] {
+			T[j][i] = A[i][j]
+		}
+	}
+	return T
+}
@@ -0,0 +1,61 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvariants(test *testing.T) {
+	/* build net:
+
+	  ┌──2──►[T1]──►(P2)──►[T2]─────┐
+	  │                             ▼
+	(P1)◄──2──[T3]◄──(P4)◄──[T4]◄──(P3)
+	  │                             ▲
+	  └──────►[T5]──────────────────┘
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	p4 := net.NewPlace("P4")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	t3 := net.NewTransition("T3")
+	t4 := net.NewTransition("T4")
+	t5 := net.NewTransition("T5")
+	p1.ConnectTo(t1, 2)
+	t1.ConnectTo(p2, 1)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p3, 1)
+	p3.ConnectTo(t4, 1)
+	t4.ConnectTo(p4, 1)
+	p4.ConnectTo(t3, 1)
+	t3.ConnectTo(p1, 2)
+	p1.ConnectTo(t5, 2)
+	t5.ConnectTo(p3, 1)
+
+	pinvs := net.PInvariants()
+	assert.Equal(test, []Invariant{{"P1": 1, "P2": 2, "P3": 2, "P4": 2}}, pinvs)
+	p1.AddTokens(4)
+	assert.Equal(test, 4, pinvs[0].Weighted(net.Marking()))
+
+	tinvs := net.TInvariants()
+	assert.ElementsMatch(test, []Invariant{
+		{"T1": 1, "T2": 1, "T3": 1, "T4": 1},
+		{"T3": 1, "T4": 1, "T5": 1},
+	}, tinvs)
+}
+
+func TestNoInvariants(test *testing.T) {
+	// (P)──►[T]  : tokens are not conserved
+	net := NewNet("TestNet")
+	p := net.NewPlace("P")
+	t := net.NewTransition("T")
+	p.ConnectTo(t, 1)
+
+	assert.Empty(test, net.PInvariants())
+	assert.Empty(test, net.TInvariants())
+}
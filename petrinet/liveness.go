@@ -0,0 +1,160 @@
+package petrinet
+
+import "fmt"
+
+// Liveness level of a Transition
+type Liveness int
+
+const (
+	Dead Liveness = iota // L0: never fires
+	L1                   // fires at least once in some firing sequence
+	L2                   // fires at least k times in some firing sequence, for every k
+	L3                   // fires infinitely often in some firing sequence
+	Live                 // L4: can fire again from every reachable marking
+)
+
+func (l Liveness) String() string {
+	switch l {
+	case Dead:
+		return "L0 (dead)"
+	case L1:
+		return "L1"
+	case L2:
+		return "L2"
+	case L3:
+		return "L3"
+	case Live:
+		return "L4 (live)"
+	}
+	return fmt.Sprintf("Liveness(%d)", int(l))
+}
+
+// Classify liveness of every Transition (by id) using strongly connected
+// components of the reachability graph from the current marking.
+// State space must be finite (at most 'AnalysisLimit' markings): in a finite
+// graph every L2 transition is also L3, so L2 is never reported.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) Liveness() (map[string]Liveness, error) {
+	g, err := n.ReachabilityGraph(AnalysisLimit)
+	if err != nil {
+		return nil, fmt.Errorf("Liveness() failed for [%s]: %v", n.id, err)
+	}
+	comp, count := g.sccs()
+	// transitions firing inside each component
+	inside := make([]map[string]bool, count)
+	bottom := make([]bool, count)
+	for c := range inside {
+		inside[c] = map[string]bool{}
+		bottom[c] = true
+	}
+	for _, e := range g.Edges {
+		if comp[e.From] == comp[e.To] {
+			inside[comp[e.From]][e.Transition] = true
+		} else {
+			bottom[comp[e.From]] = false // component can be left
+		}
+	}
+
+	levels := make(map[string]Liveness, len(n.transitions))
+	for _, t := range n.transitions {
+		levels[t.Id()] = Dead
+	}
+	for _, e := range g.Edges {
+		levels[e.Transition] = L1
+	}
+	for c := 0; c < count; c++ {
+		for id := range inside[c] {
+			levels[id] = L3
+		}
+	}
+	for _, t := range n.transitions {
+		live := levels[t.Id()] == L3
+		for c := 0; c < count && live; c++ {
+			if bottom[c] && !inside[c][t.Id()] {
+				live = false
+			}
+		}
+		if live {
+			levels[t.Id()] = Live
+		}
+	}
+	return levels, NoError
+}
+
+// Strongly connected components of reachability graph
+func (g *ReachabilityGraph) sccs() ([]int, int) {
+	succ := make([][]int, len(g.Nodes))
+	for _, e := range g.Edges {
+		succ[e.From] = append(succ[e.From], e.To)
+	}
+	return stronglyConnected(succ)
+}
+
+// Strongly connected components (Tarjan, iterative version) of the graph
+// given by successors of every node.
+// Returns component of every node and number of components. Components are
+// numbered in reverse topological order (bottom components first).
+func stronglyConnected(succ [][]int) ([]int, int) {
+	N := len(succ)
+	index := make([]int, N)
+	low := make([]int, N)
+	comp := make([]int, N)
+	onStack := make([]bool, N)
+	for i := range index {
+		index[i] = -1
+	}
+	stack := []int{}
+	counter, count := 0, 0
+
+	type frame struct{ node, edge int }
+	for root := 0; root < N; root++ {
+		if index[root] >= 0 {
+			continue
+		}
+		calls := []frame{{root, 0}}
+		index[root], low[root] = counter, counter
+		counter++
+		stack = append(stack, root)
+		onStack[root] = true
+		for len(calls) > 0 {
+			f := &calls[len(calls)-1]
+			out := succ[f.node]
+			if f.edge < len(out) {
+				next := out[f.edge]
+				f.edge++
+				if index[next] < 0 {
+					index[next], low[next] = counter, counter
+					counter++
+					stack = append(stack, next)
+					onStack[next] = true
+					calls = append(calls, frame{next, 0})
+				} else if onStack[next] && index[next] < low[f.node] {
+					low[f.node] = index[next]
+				}
+				continue
+			}
+			// all successors visited
+			v := f.node
+			calls = calls[:len(calls)-1]
+			if len(calls) > 0 {
+				parent := calls[len(calls)-1].node
+				if low[v] < low[parent] {
+					low[parent] = low[v]
+				}
+			}
+			if low[v] == index[v] {
+				for {
+					w := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					onStack[w] = false
+					comp[w] = count
+					if w == v {
+						break
+					}
+				}
+				count++
+			}
+		}
+	}
+	return comp, count
+}
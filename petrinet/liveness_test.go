@@ -0,0 +1,56 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveness(test *testing.T) {
+	/* build net:
+
+	(P1)──►[T1]──►(P2)──►[T2]──►(P1)
+	  │
+	  └───►[T3]──►(P3)
+
+	(P4)──►[T4]
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	p4 := net.NewPlace("P4")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	t3 := net.NewTransition("T3")
+	t4 := net.NewTransition("T4")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p1, 1)
+	p1.ConnectTo(t3, 1)
+	t3.ConnectTo(p3, 1)
+	p4.ConnectTo(t4, 1)
+	p1.AddTokens(1)
+
+	levels, err := net.Liveness()
+	assert.NoError(test, err)
+	assert.Equal(test, map[string]Liveness{"T1": L3, "T2": L3, "T3": L1, "T4": Dead}, levels)
+
+	// without exit transition T3 the cycle is live
+	net = NewNet("TestNet")
+	p1 = net.NewPlace("P1")
+	p2 = net.NewPlace("P2")
+	t1 = net.NewTransition("T1")
+	t2 = net.NewTransition("T2")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p1, 1)
+	p1.AddTokens(1)
+
+	levels, err = net.Liveness()
+	assert.NoError(test, err)
+	assert.Equal(test, map[string]Liveness{"T1": Live, "T2": Live}, levels)
+}
@@ -0,0 +1,192 @@
+package petrinet
+
+import "math/big"
+
+// Linear programming helpers used by structural analysis.
+// Exact (rational) arithmetic is used, so results are not affected by rounding.
+
+// Max number of branch&bound sub-problems solved by 'ilpFeasible()'
+const ilpMaxNodes = 1000
+
+// Bound on a single variable of an integer problem
+type ilpBound struct {
+	variable int
+	value    int
+	upper    bool // x <= value if true, x >= value otherwise
+}
+
+// Test if A·x = b has a non-negative integer solution (branch & bound).
+// 'decided' is false when the search was stopped after 'ilpMaxNodes' sub-problems.
+func ilpFeasible(A [][]int, b []int) (feasible bool, decided bool) {
+	nvars := 0
+	if len(A) > 0 {
+		nvars = len(A[0])
+	}
+	stack := [][]ilpBound{{}}
+	for nodes := 0; len(stack) > 0; nodes++ {
+		if nodes >= ilpMaxNodes {
+			return false, false
+		}
+		bounds := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		x := lpFeasible(withBounds(A, b, nvars, bounds))
+		if x == nil {
+			continue // no rational solution: prune
+		}
+		j := firstFractional(x[:nvars])
+		if j < 0 {
+			return true, true // integer solution found
+		}
+		floor := new(big.Int).Quo(x[j].Num(), x[j].Denom()) // x >= 0: truncation is floor
+		lo := int(floor.Int64())
+		stack = append(stack,
+			append(append([]ilpBound{}, bounds...), ilpBound{j, lo, true}),
+			append(append([]ilpBound{}, bounds...), ilpBound{j, lo + 1, false}))
+	}
+	return false, true
+}
+
+// Build rational problem A'·x' = b' adding a row and a slack variable for every bound
+func withBounds(A [][]int, b []int, nvars int, bounds []ilpBound) ([][]*big.Rat, []*big.Rat) {
+	ncols := nvars + len(bounds)
+	rows := make([][]*big.Rat, 0, len(A)+len(bounds))
+	rhs := make([]*big.Rat, 0, len(A)+len(bounds))
+	for i, row := range A {
+		r := zeroRats(ncols)
+		for j, v := range row {
+			r[j].SetInt64(int64(v))
+		}
+		rows = append(rows, r)
+		rhs = append(rhs, big.NewRat(int64(b[i]), 1))
+	}
+	for k, bound := range bounds {
+		r := zeroRats(ncols)
+		r[bound.variable].SetInt64(1)
+		if bound.upper {
+			r[nvars+k].SetInt64(1) // x + s = value
+		} else {
+			r[nvars+k].SetInt64(-1) // x - s = value
+		}
+		rows = append(rows, r)
+		rhs = append(rhs, big.NewRat(int64(bound.value), 1))
+	}
+	return rows, rhs
+}
+
+func zeroRats(n int) []*big.Rat {
+	r := make([]*big.Rat, n)
+	for i := range r {
+		r[i] = new(big.Rat)
+	}
+	return r
+}
+
+func firstFractional(x []*big.Rat) int {
+	for j, v := range x {
+		if !v.IsInt() {
+			return j
+		}
+	}
+	return -1
+}
+
+// Find a solution of A·x = b, x >= 0 over rationals (phase one of simplex
+// method, with Bland's rule to avoid cycling). Returns nil if infeasible.
+func lpFeasible(A [][]*big.Rat, b []*big.Rat) []*big.Rat {
+	m := len(A)
+	n := 0
+	if m > 0 {
+		n = len(A[0])
+	}
+	// tableau: [ A | I (artificial) | b ], with b >= 0
+	width := n + m + 1
+	T := make([][]*big.Rat, m)
+	basis := make([]int, m)
+	for i := range A {
+		T[i] = zeroRats(width)
+		sign := big.NewRat(1, 1)
+		if b[i].Sign() < 0 {
+			sign.SetInt64(-1)
+		}
+		for j := 0; j < n; j++ {
+			T[i][j].Mul(A[i][j], sign)
+		}
+		T[i][n+i].SetInt64(1)
+		T[i][width-1].Mul(b[i], sign)
+		basis[i] = n + i
+	}
+	// reduced costs of phase one objective (minimize sum of artificial variables)
+	z := zeroRats(width)
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			z[j].Sub(z[j], T[i][j])
+		}
+	}
+	for i := 0; i < m; i++ {
+		z[width-1].Sub(z[width-1], T[i][width-1])
+	}
+
+	for {
+		// entering variable: lowest index with negative reduced cost
+		enter := -1
+		for j := 0; j < width-1; j++ {
+			if z[j].Sign() < 0 {
+				enter = j
+				break
+			}
+		}
+		if enter < 0 {
+			break // optimum reached
+		}
+		// leaving variable: minimum ratio test, ties broken by lowest basis index
+		leave := -1
+		var best *big.Rat
+		for i := 0; i < m; i++ {
+			if T[i][enter].Sign() <= 0 {
+				continue
+			}
+			ratio := new(big.Rat).Quo(T[i][width-1], T[i][enter])
+			if leave < 0 || ratio.Cmp(best) < 0 || (ratio.Cmp(best) == 0 && basis[i] < basis[leave]) {
+				leave, best = i, ratio
+			}
+		}
+		if leave < 0 {
+			break // unbounded direction: cannot happen in phase one
+		}
+		pivot(T, z, leave, enter)
+		basis[leave] = enter
+	}
+	if z[width-1].Sign() != 0 {
+		return nil // some artificial variable is still positive
+	}
+	x := zeroRats(n)
+	for i, j := range basis {
+		if j < n {
+			x[j].Set(T[i][width-1])
+		}
+	}
+	return x
+}
+
+func pivot(T [][]*big.Rat, z []*big.Rat, row, col int) {
+	p := new(big.Rat).Set(T[row][col])
+	for j := range T[row] {
+		T[row][j].Quo(T[row][j], p)
+	}
+	eliminate := func(r []*big.Rat) {
+		f := new(big.Rat).Set(r[col])
+		if f.Sign() == 0 {
+			return
+		}
+		for j := range r {
+			r[j].Sub(r[j], new(big.Rat).Mul(f, T[row][j]))
+		}
+	}
+	for i := range T {
+		if i != row {
+			eliminate(T[i])
+		}
+	}
+	eliminate(z)
+}
@@ -0,0 +1,406 @@
+package petrinet
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Check LTL property (see 'formula' for syntax) on every path from the
+// initial marking. Negation of the property is translated into a generalized
+// Büchi automaton (GPVW tableau construction) and its product with the
+// reachability graph is searched for an accepting lasso: if found, it's
+// returned as counterexample ('Prefix' followed by 'Cycle' repeated forever).
+// Paths reaching a dead marking stay there forever with 'Stutter' steps.
+// State space must be finite (at most 'AnalysisLimit' markings).
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) CheckLTL(property string) (*CheckResult, error) {
+	f, err := n.parseFormula(property, true)
+	if err != nil {
+		return nil, fmt.Errorf("CheckLTL() failed for [%s]: %v", n.id, err)
+	}
+	mc, err := n.newModelChecker()
+	if err != nil {
+		return nil, fmt.Errorf("CheckLTL() failed for [%s]: %v", n.id, err)
+	}
+	a := newBuchi(nnf(f, true))
+	prefix, cycle, found := mc.acceptingLasso(a)
+	if !found {
+		return &CheckResult{Holds: true}, NoError
+	}
+	return &CheckResult{Holds: false, Prefix: prefix, Cycle: cycle}, NoError
+}
+
+// Negation normal form using only literals, &, |, X, U, R.
+// If 'negate' the result is equivalent to !f
+func nnf(f *formula, negate bool) *formula {
+	switch f.op {
+	case "true", "false":
+		if negate == (f.op == "true") {
+			return &formula{op: "false"}
+		}
+		return &formula{op: "true"}
+	case "atom", "enabled":
+		if negate {
+			return &formula{op: "!", left: f}
+		}
+		return f
+	case "!":
+		return nnf(f.left, !negate)
+	case "&", "|":
+		op := f.op
+		if negate {
+			op = map[string]string{"&": "|", "|": "&"}[op]
+		}
+		return &formula{op: op, left: nnf(f.left, negate), right: nnf(f.right, negate)}
+	case "->":
+		return nnf(&formula{op: "|", left: &formula{op: "!", left: f.left}, right: f.right}, negate)
+	case "X":
+		return &formula{op: "X", left: nnf(f.left, negate)}
+	case "F": // F f = true U f
+		return nnf(&formula{op: "U", left: &formula{op: "true"}, right: f.left}, negate)
+	case "G": // G f = false R f
+		return nnf(&formula{op: "R", left: &formula{op: "false"}, right: f.left}, negate)
+	case "U", "R":
+		op := f.op
+		if negate {
+			op = map[string]string{"U": "R", "R": "U"}[op]
+		}
+		return &formula{op: op, left: nnf(f.left, negate), right: nnf(f.right, negate)}
+	}
+	logger.Panicf("Operator [%s] not supported in LTL", f.op)
+	return nil
+}
+
+// set of formulas, indexed by their string representation
+type formulaSet map[string]*formula
+
+func (fs formulaSet) clone() formulaSet {
+	c := formulaSet{}
+	for k, f := range fs {
+		c[k] = f
+	}
+	return c
+}
+func (fs formulaSet) key() string {
+	keys := make([]string, 0, len(fs))
+	for k := range fs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ";")
+}
+
+// Node of generalized Büchi automaton: literals in 'old' must hold in the
+// marking read when entering the node.
+type buchiNode struct {
+	id       int
+	incoming map[int]bool // predecessors (0 is the initial pseudo node)
+	new      formulaSet
+	old      formulaSet
+	next     formulaSet
+}
+
+type buchi struct {
+	nodes      []*buchiNode
+	acceptance [][]bool // for every U subformula: accepting nodes
+}
+
+func newBuchi(f *formula) *buchi {
+	a := &buchi{}
+	a.expand(&buchiNode{
+		id:       1,
+		incoming: map[int]bool{0: true},
+		new:      formulaSet{f.String(): f},
+		old:      formulaSet{},
+		next:     formulaSet{},
+	})
+	// acceptance condition: for every 'f U g' some node without it or with 'g'
+	untils := formulaSet{}
+	collectUntils(f, untils)
+	for _, u := range untils {
+		accepting := make([]bool, len(a.nodes))
+		for i, node := range a.nodes {
+			_, hasU := node.old[u.String()]
+			_, hasG := node.old[u.right.String()]
+			accepting[i] = !hasU || hasG
+		}
+		a.acceptance = append(a.acceptance, accepting)
+	}
+	return a
+}
+
+func collectUntils(f *formula, untils formulaSet) {
+	if f == nil {
+		return
+	}
+	if f.op == "U" {
+		untils[f.String()] = f
+	}
+	collectUntils(f.left, untils)
+	collectUntils(f.right, untils)
+}
+
+// GPVW tableau expansion
+func (a *buchi) expand(node *buchiNode) {
+	if len(node.new) == 0 {
+		for _, nd := range a.nodes {
+			if nd.old.key() == node.old.key() && nd.next.key() == node.next.key() {
+				for in := range node.incoming {
+					nd.incoming[in] = true
+				}
+				return
+			}
+		}
+		node.id = len(a.nodes) + 1
+		a.nodes = append(a.nodes, node)
+		a.expand(&buchiNode{
+			incoming: map[int]bool{node.id: true},
+			new:      node.next.clone(),
+			old:      formulaSet{},
+			next:     formulaSet{},
+		})
+		return
+	}
+	// pick a formula to process
+	var key string
+	for k := range node.new {
+		if key == "" || k < key {
+			key = k
+		}
+	}
+	f := node.new[key]
+	delete(node.new, key)
+	if _, done := node.old[key]; done {
+		a.expand(node)
+		return
+	}
+	switch f.op {
+	case "true", "false", "atom", "enabled", "!":
+		if f.op == "false" || node.contradicts(f) {
+			return // node discarded
+		}
+		node.old[key] = f
+		a.expand(node)
+	case "&":
+		node.old[key] = f
+		node.addNew(f.left)
+		node.addNew(f.right)
+		a.expand(node)
+	case "X":
+		node.old[key] = f
+		node.next[f.left.String()] = f.left
+		a.expand(node)
+	case "|", "U", "R":
+		node2 := &buchiNode{
+			incoming: copyInts(node.incoming),
+			new:      node.new.clone(),
+			old:      node.old.clone(),
+			next:     node.next.clone(),
+		}
+		node.old[key] = f
+		node2.old[key] = f
+		switch f.op {
+		case "|":
+			node.addNew(f.left)
+			node2.addNew(f.right)
+		case "U": // f U g = g | (f & X(f U g))
+			node.addNew(f.left)
+			node.next[key] = f
+			node2.addNew(f.right)
+		case "R": // f R g = g & (f | X(f R g))
+			node.addNew(f.right)
+			node.next[key] = f
+			node2.addNew(f.left)
+			node2.addNew(f.right)
+		}
+		a.expand(node)
+		a.expand(node2)
+	}
+}
+
+func (node *buchiNode) addNew(f *formula) {
+	if _, done := node.old[f.String()]; !done {
+		node.new[f.String()] = f
+	}
+}
+
+// Test if literal contradicts a literal of the node
+func (node *buchiNode) contradicts(f *formula) bool {
+	var negation string
+	switch f.op {
+	case "!":
+		negation = f.left.String()
+	case "atom", "enabled":
+		negation = (&formula{op: "!", left: f}).String()
+	default:
+		return false
+	}
+	_, found := node.old[negation]
+	return found
+}
+
+func copyInts(m map[int]bool) map[int]bool {
+	c := map[int]bool{}
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// Test if marking of node 's' satisfies literals of automaton node
+func (mc *modelChecker) accepts(s int, node *buchiNode) bool {
+	for _, f := range node.old {
+		switch f.op {
+		case "atom", "enabled", "!", "true":
+			if !f.eval(mc.net, mc.g.states[s]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Search product of reachability graph and Büchi automaton for a reachable
+// cycle visiting every acceptance set.
+func (mc *modelChecker) acceptingLasso(a *buchi) ([]string, []string, bool) {
+	// automaton successors of every node (index in a.nodes)
+	next := make([][]int, len(a.nodes)+1) // position 0 is the initial pseudo node
+	for j, node := range a.nodes {
+		for in := range node.incoming {
+			next[in] = append(next[in], j)
+		}
+	}
+	for _, succ := range next {
+		sort.Ints(succ)
+	}
+	// explore product: state = (graph node, automaton node)
+	type pair struct{ s, q int }
+	index := map[pair]int{}
+	pairs := []pair{}
+	succ := [][]int{}
+	labels := [][]string{}
+	add := func(p pair) int {
+		if id, found := index[p]; found {
+			return id
+		}
+		index[p] = len(pairs)
+		pairs = append(pairs, p)
+		succ = append(succ, nil)
+		labels = append(labels, nil)
+		return len(pairs) - 1
+	}
+	initial := []int{}
+	for _, q := range next[0] {
+		if mc.accepts(0, a.nodes[q]) {
+			initial = append(initial, add(pair{0, q}))
+		}
+	}
+	for i := 0; i < len(pairs); i++ {
+		cur := pairs[i]
+		for k, s := range mc.succ[cur.s] {
+			for _, q := range next[a.nodes[cur.q].id] {
+				if mc.accepts(s, a.nodes[q]) {
+					to := add(pair{s, q})
+					succ[i] = append(succ[i], to)
+					labels[i] = append(labels[i], mc.labels[cur.s][k])
+				}
+			}
+		}
+	}
+
+	comp, count := stronglyConnected(succ)
+	for c := 0; c < count; c++ {
+		members := make([]bool, len(pairs))
+		nontrivial := false
+		for i := range pairs {
+			if comp[i] != c {
+				continue
+			}
+			members[i] = true
+			for _, to := range succ[i] {
+				nontrivial = nontrivial || comp[to] == c
+			}
+		}
+		if !nontrivial {
+			continue
+		}
+		// every acceptance set must be visited by the cycle
+		targets := [][]bool{}
+		fair := true
+		for _, accepting := range a.acceptance {
+			target := make([]bool, len(pairs))
+			found := false
+			for i := range pairs {
+				target[i] = members[i] && accepting[pairs[i].q]
+				found = found || target[i]
+			}
+			fair = fair && found
+			targets = append(targets, target)
+		}
+		if !fair {
+			continue
+		}
+		// prefix: from initial states to the component
+		all := make([]bool, len(pairs))
+		for i := range all {
+			all[i] = true
+		}
+		start, prefix := productPath(succ, labels, initial, all, members)
+		// cycle: from start through every acceptance set and back to start
+		cycle := []string{}
+		cur := start
+		for _, target := range targets {
+			var path []string
+			cur, path = productPath(succ, labels, []int{cur}, members, target)
+			cycle = append(cycle, path...)
+		}
+		// close the cycle with at least one step
+		back := make([]bool, len(pairs))
+		back[start] = true
+		froms := []int{}
+		fromLabels := []string{}
+		for k, to := range succ[cur] {
+			if members[to] {
+				froms = append(froms, to)
+				fromLabels = append(fromLabels, labels[cur][k])
+			}
+		}
+		for k, from := range froms {
+			if _, path := productPath(succ, labels, []int{from}, members, back); path != nil {
+				cycle = append(cycle, fromLabels[k])
+				cycle = append(cycle, path...)
+				break
+			}
+		}
+		return prefix, cycle, true
+	}
+	return nil, nil, false
+}
+
+// Shortest path from one of 'froms' to a 'target' node, visiting 'through' nodes only.
+// Returns reached node and transition labels of the path (nil if not found).
+func productPath(succ [][]int, labels [][]string, froms []int, through, target []bool) (int, []string) {
+	paths := map[int][]string{}
+	queue := []int{}
+	for _, from := range froms {
+		paths[from] = []string{}
+		queue = append(queue, from)
+	}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if target[cur] {
+			return cur, paths[cur]
+		}
+		for k, next := range succ[cur] {
+			if _, found := paths[next]; !found && through[next] {
+				path := make([]string, len(paths[cur]), len(paths[cur])+1)
+				copy(path, paths[cur])
+				paths[next] = append(path, labels[cur][k])
+				queue = append(queue, next)
+			}
+		}
+	}
+	return -1, nil
+}
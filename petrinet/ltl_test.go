@@ -0,0 +1,61 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLTL(test *testing.T) {
+	/* build net:
+
+	(P1)──►[T1]──►(P2)──►[T2]──►(P1)
+	  │
+	  └───►[T3]──►(P3)
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	t3 := net.NewTransition("T3")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p1, 1)
+	p1.ConnectTo(t3, 1)
+	t3.ConnectTo(p3, 1)
+	p1.AddTokens(1)
+
+	r, err := net.CheckLTL("G(P1 <= 1 & P2 <= 1)")
+	assert.NoError(test, err)
+	assert.True(test, r.Holds)
+
+	r, err = net.CheckLTL("F P3 > 0")
+	assert.NoError(test, err)
+	assert.False(test, r.Holds)
+	assert.Empty(test, r.Prefix)
+	assert.Equal(test, []string{"T1", "T2"}, r.Cycle)
+
+	r, err = net.CheckLTL("F G P3 > 0 | G F Enabled(T1)")
+	assert.NoError(test, err)
+	assert.True(test, r.Holds)
+
+	r, err = net.CheckLTL("P3 = 0 U P3 = 1")
+	assert.NoError(test, err)
+	assert.False(test, r.Holds)
+
+	r, err = net.CheckLTL("G(P2 > 0 -> X P1 > 0)")
+	assert.NoError(test, err)
+	assert.True(test, r.Holds)
+
+	r, err = net.CheckLTL("X X P3 = 0")
+	assert.NoError(test, err)
+	assert.False(test, r.Holds)
+	assert.Equal(test, []string{"T3", Stutter}, append(r.Prefix, r.Cycle...)[:2])
+
+	_, err = net.CheckLTL("G(P1 + P2 <= 1)")
+	assert.Error(test, err)
+}
@@ -0,0 +1,104 @@
+package petrinet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Marking maps Place ids to their number of tokens
+type Marking map[string]int
+
+func (m Marking) String() string {
+	return fmt.Sprintf("%v", map[string]int(m))
+}
+
+/*
+Net state used by analysis algorithms: tokens of every Place, in the
+same order of Net.places. Analysis never touches real Place tokens.
+*/
+type state []int
+
+// Unique key used to index states in maps
+func (s state) key() string {
+	var sb strings.Builder
+	for i, toks := range s {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		fmt.Fprintf(&sb, "%d", toks)
+	}
+	return sb.String()
+}
+func (s state) clone() state {
+	c := make(state, len(s))
+	copy(c, s)
+	return c
+}
+
+// Snapshot of current tokens in the net.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) currentState() state {
+	s := make(state, len(n.places))
+	for i, p := range n.places {
+		s[i] = p.Tokens()
+	}
+	return s
+}
+
+// Set tokens of every place as in state 's'
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) restore(s state) {
+	for i, p := range n.places {
+		if delta := s[i] - p.Tokens(); delta != 0 {
+			p.AddTokens(delta)
+		}
+	}
+}
+func (n *Net) tokensAt(s state) func(PlaceI) int {
+	return func(p PlaceI) int {
+		return s[n.placeIdx[p]]
+	}
+}
+func (n *Net) toMarking(s state) Marking {
+	m := make(Marking, len(n.places))
+	for i, p := range n.places {
+		m[p.Id()] = s[i]
+	}
+	return m
+}
+
+// Current marking of the net
+func (n *Net) Marking() Marking {
+	return n.toMarking(n.currentState())
+}
+
+// Test if transition is enabled in state 's'.
+// Same semantic used by 'firingAttempt()' on real places.
+func (n *Net) isEnabledAt(t *Transition, s state) bool {
+	for _, arc := range t.arcs_in {
+		if !arcEnabledBy(arc, s[n.placeIdx[arc.Place()]]) {
+			return false
+		}
+	}
+	if t.exceedsCapacity(n.tokensAt(s)) != nil {
+		return false
+	}
+	return t.guard == nil || t.guard(n.localMarkingAt(t, s))
+}
+
+// Compute state reached firing (enabled) transition from state 's'.
+// Places with Omega tokens keep Omega tokens.
+func (n *Net) fireAt(t *Transition, s state) state {
+	next := s.clone()
+	for _, arc := range t.arcs_in {
+		if i := n.placeIdx[arc.Place()]; next[i] != Omega {
+			next[i] -= arcWeight(arc)
+		}
+	}
+	for _, arc := range t.arcs_out {
+		if i := n.placeIdx[arc.Place()]; next[i] != Omega {
+			next[i] += arcWeight(arc)
+		}
+	}
+	return next
+}
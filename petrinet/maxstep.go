@@ -0,0 +1,114 @@
+package petrinet
+
+import (
+	"sync/atomic"
+
+	"github.com/golang-collections/collections/set"
+)
+
+/*
+Maximal-step semantics: in every round a maximal set of enabled transitions
+not consuming tokens from a shared place (nor adding tokens to a shared place
+with a capacity) fires simultaneously. All the
+transitions of a step are enabled by the marking before the step (EnableArcs
+included), tokens are consumed and then produced, so a step is recorded as a
+single pair of animation frames.
+*/
+
+// Synchronously fire a maximal set of non-conflicting enabled transitions.
+// Transitions in conflict are picked by net policy (see 'SetChooser()').
+// Returns the transitions fired (in net order), empty if no transition is enabled.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) MaxStep() []TransitionI {
+	candidates := n.EnabledTransitions()
+	chosen := map[TransitionI]bool{}
+	for len(candidates) > 0 {
+		t := n.choose(candidates).(*Transition)
+		chosen[t] = true
+		free := []TransitionI{}
+		for _, u := range candidates {
+			if u != t && !inConflict(t, u.(*Transition)) && !shareCapacity(t, u.(*Transition)) {
+				free = append(free, u)
+			}
+		}
+		candidates = free
+	}
+	step := []TransitionI{}
+	for _, t := range n.transitions {
+		if chosen[t] {
+			step = append(step, t)
+		}
+	}
+	if len(step) > 0 {
+		dispatch(fireStep(n, step)...)
+	}
+	return step
+}
+
+// Test if transitions add tokens to a shared place with a capacity: each of
+// them could fit in it, but not both
+func shareCapacity(t, u *Transition) bool {
+	for _, a := range t.arcs_out {
+		if a.Place().Capacity() == undef {
+			continue
+		}
+		for _, b := range u.arcs_out {
+			if b.Place() == a.Place() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Atomically fire non-conflicting transitions, enabled in current marking.
+// Returns the firing events for actions.
+func fireStep(n *Net, step []TransitionI) []*FiringEvent {
+	all_places := set.New()
+	for _, t := range step {
+		uniquePlaces(t.(*Transition)).Do(func(p interface{}) {
+			all_places.Insert(p)
+		})
+	}
+	first := step[0].(*Transition)
+	lockPlaces(first, all_places)
+	defer unlockPlaces(first, all_places)
+	defer refreshEnabling(placesOf(all_places), all_places)
+
+	preDot := n.buildDot(step...)
+	for _, t := range step {
+		if !t.(*Transition).isEnabled() {
+			logger.Panicf("Transition [%s] not enabled in step", t.Id())
+		}
+	}
+	befores := make([]Marking, len(step))
+	for k, t := range step {
+		if len(t.(*Transition).actions) > 0 {
+			befores[k] = t.(*Transition).localMarking()
+		}
+	}
+	inputs := []PlaceI{}
+	for _, t := range step {
+		atomic.StoreUint64(&t.(*Transition).enabledSince, 0)
+		t.(*Transition).enabledAt = undef
+		for _, arc := range t.(*Transition).arcs_in {
+			arc.ConsumeTokens()
+			inputs = append(inputs, arc.Place())
+		}
+	}
+	// same time semantic as 'fireLocked()'
+	forConnected(inputs, all_places, (*Transition).resetTimer)
+	for _, t := range step {
+		for _, arc := range t.(*Transition).arcs_out {
+			arc.FireTokens()
+		}
+	}
+	postDot := n.buildDot()
+	n.addAnimationFrame([]frame{{preDot, 200}, {postDot, 200}})
+
+	events := make([]*FiringEvent, len(step))
+	for k, t := range step {
+		events[k] = t.(*Transition).firingEvent(befores[k])
+	}
+	return events
+}
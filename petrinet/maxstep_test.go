@@ -0,0 +1,87 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxStep(test *testing.T) {
+	net := buildIndependentNet(3)
+	net.EnableAnimation(true)
+	step := net.MaxStep()
+	assert.Equal(test, 3, len(step))
+	assert.Equal(test, Marking{"P0": 0, "P1": 0, "P2": 0, "Q0": 1, "Q1": 1, "Q2": 1}, net.Marking())
+	assert.Equal(test, 2, len(net.frames)) // one frame pair per step
+	assert.Equal(test, 0, len(net.MaxStep()))
+}
+
+func TestMaxStepConflict(test *testing.T) {
+	/* build net:
+
+	     ┌──►[T1]──►(A)
+	(P)──┤
+	     └──►[T2]──►(B)
+
+	(Q)─────►[T3]──►(C)
+
+	*/
+	build := func() (*Net, []TransitionI) {
+		net := NewNet("TestNet")
+		p := net.NewPlace("P")
+		q := net.NewPlace("Q")
+		a := net.NewPlace("A")
+		b := net.NewPlace("B")
+		c := net.NewPlace("C")
+		t1 := net.NewTransition("T1")
+		t2 := net.NewTransition("T2")
+		t3 := net.NewTransition("T3")
+		p.ConnectTo(t1, 1)
+		t1.ConnectTo(a, 1)
+		p.ConnectTo(t2, 1)
+		t2.ConnectTo(b, 1)
+		q.ConnectTo(t3, 1)
+		t3.ConnectTo(c, 1)
+		p.AddTokens(1)
+		q.AddTokens(1)
+		return net, []TransitionI{t1, t2, t3}
+	}
+	net, ts := build()
+	assert.Equal(test, []TransitionI{ts[0], ts[2]}, net.MaxStep())
+
+	net, ts = build()
+	ts[1].SetPriority(1)
+	net.SetChooser(NewPriorityChooser(nil))
+	assert.Equal(test, []TransitionI{ts[1], ts[2]}, net.MaxStep())
+	assert.Equal(test, Marking{"P": 0, "Q": 0, "A": 0, "B": 1, "C": 1}, net.Marking())
+}
+
+func TestMaxStepEnableArcs(test *testing.T) {
+	/* build net:
+
+	(A)──►[T1]──►(B)
+
+	(C)──►[T2]──►(D)
+
+	T2 is enabled by A (at least 1 token) and inhibited by B
+	*/
+	net := NewNet("TestNet")
+	a := net.NewPlace("A")
+	b := net.NewPlace("B")
+	c := net.NewPlace("C")
+	d := net.NewPlace("D")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	a.ConnectTo(t1, 1)
+	t1.ConnectTo(b, 1)
+	c.ConnectTo(t2, 1)
+	t2.EnabledBy(a, t2.SetLow(1))
+	t2.InhibitedBy(b)
+	t2.ConnectTo(d, 1)
+	a.AddTokens(1)
+	c.AddTokens(1)
+
+	// enable arcs read the marking before the step
+	assert.Equal(test, []TransitionI{t1, t2}, net.MaxStep())
+	assert.Equal(test, Marking{"A": 0, "B": 1, "C": 0, "D": 1}, net.Marking())
+}
@@ -0,0 +1,149 @@
+package petrinet
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+/*
+Multi-valued decision diagram: set of states where level 'i' is the number of
+tokens (0..k) of the i-th Place. Nodes are shared (reduced and ordered
+diagram), so two sets are equal if and only if they have the same node id.
+Node 0 is the empty set, node 1 the terminal node (set containing the empty
+suffix of a state).
+*/
+type mdd struct {
+	levels     int
+	k          int
+	nodes      []mddNode
+	unique     map[string]int
+	unionCache map[[2]int]int
+	minusCache map[[2]int]int
+}
+
+type mddNode struct {
+	level    int
+	children []int // node id for every number of tokens 0..k
+}
+
+const (
+	mddEmpty    = 0
+	mddTerminal = 1
+)
+
+func newMdd(levels, k int) *mdd {
+	return &mdd{
+		levels:     levels,
+		k:          k,
+		nodes:      []mddNode{{level: levels}, {level: levels}},
+		unique:     map[string]int{},
+		unionCache: map[[2]int]int{},
+		minusCache: map[[2]int]int{},
+	}
+}
+
+// Unique node with given children
+func (d *mdd) node(level int, children []int) int {
+	empty := true
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d:", level)
+	for _, c := range children {
+		empty = empty && c == mddEmpty
+		fmt.Fprintf(&sb, "%d,", c)
+	}
+	if empty {
+		return mddEmpty
+	}
+	key := sb.String()
+	if id, found := d.unique[key]; found {
+		return id
+	}
+	d.nodes = append(d.nodes, mddNode{level: level, children: children})
+	d.unique[key] = len(d.nodes) - 1
+	return len(d.nodes) - 1
+}
+
+// Set containing a single state
+func (d *mdd) singleton(s state) int {
+	id := mddTerminal
+	for level := d.levels - 1; level >= 0; level-- {
+		children := make([]int, d.k+1)
+		children[s[level]] = id
+		id = d.node(level, children)
+	}
+	return id
+}
+
+func (d *mdd) union(a, b int) int {
+	if a == mddEmpty || a == b {
+		return b
+	}
+	if b == mddEmpty {
+		return a
+	}
+	if a > b {
+		a, b = b, a
+	}
+	if r, found := d.unionCache[[2]int{a, b}]; found {
+		return r
+	}
+	na, nb := d.nodes[a], d.nodes[b]
+	children := make([]int, d.k+1)
+	for v := range children {
+		children[v] = d.union(na.children[v], nb.children[v])
+	}
+	r := d.node(na.level, children)
+	d.unionCache[[2]int{a, b}] = r
+	return r
+}
+
+// States in 'a' and not in 'b'
+func (d *mdd) minus(a, b int) int {
+	if a == mddEmpty || a == b {
+		return mddEmpty
+	}
+	if b == mddEmpty {
+		return a
+	}
+	if r, found := d.minusCache[[2]int{a, b}]; found {
+		return r
+	}
+	na, nb := d.nodes[a], d.nodes[b]
+	children := make([]int, d.k+1)
+	for v := range children {
+		children[v] = d.minus(na.children[v], nb.children[v])
+	}
+	r := d.node(na.level, children)
+	d.minusCache[[2]int{a, b}] = r
+	return r
+}
+
+// Number of states in the set
+func (d *mdd) count(a int, memo map[int]*big.Int) *big.Int {
+	if a == mddEmpty {
+		return big.NewInt(0)
+	}
+	if a == mddTerminal {
+		return big.NewInt(1)
+	}
+	if c, found := memo[a]; found {
+		return c
+	}
+	c := big.NewInt(0)
+	for _, child := range d.nodes[a].children {
+		c.Add(c, d.count(child, memo))
+	}
+	memo[a] = c
+	return c
+}
+
+func (d *mdd) contains(a int, s state) bool {
+	for level := 0; a != mddEmpty && a != mddTerminal; level++ {
+		if s[level] > d.k {
+			return false
+		}
+		a = d.nodes[a].children[s[level]]
+	}
+	return a == mddTerminal
+}
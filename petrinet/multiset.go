@@ -0,0 +1,84 @@
+package petrinet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Multiset of values, remembering the order in which distinct values were first added
+type Multiset[T comparable] struct {
+	counts map[T]int
+	values []T // distinct values
+}
+
+func NewMultiset[T comparable](values ...T) *Multiset[T] {
+	m := &Multiset[T]{counts: map[T]int{}}
+	for _, v := range values {
+		m.Add(v, 1)
+	}
+	return m
+}
+
+// Add 'k' copies of 'v'
+func (m *Multiset[T]) Add(v T, k int) {
+	if k <= 0 {
+		return
+	}
+	if m.counts[v] == 0 {
+		m.values = append(m.values, v)
+	}
+	m.counts[v] += k
+}
+
+// Remove 'k' copies of 'v', if there are enough of them
+func (m *Multiset[T]) Remove(v T, k int) bool {
+	if m.counts[v] < k {
+		return false
+	}
+	if m.counts[v] -= k; m.counts[v] == 0 {
+		delete(m.counts, v)
+		for i, u := range m.values {
+			if u == v {
+				m.values = append(m.values[:i], m.values[i+1:]...)
+				break
+			}
+		}
+	}
+	return true
+}
+
+// Copies of 'v'
+func (m *Multiset[T]) Count(v T) int {
+	return m.counts[v]
+}
+
+// Number of values, counting copies
+func (m *Multiset[T]) Size() int {
+	size := 0
+	for _, k := range m.counts {
+		size += k
+	}
+	return size
+}
+
+// Distinct values
+func (m *Multiset[T]) Values() []T {
+	return append([]T{}, m.values...)
+}
+
+func (m *Multiset[T]) clone() *Multiset[T] {
+	c := NewMultiset[T]()
+	for _, v := range m.values {
+		c.Add(v, m.counts[v])
+	}
+	return c
+}
+
+// e.g. "{2`a, 1`b}"
+func (m *Multiset[T]) String() string {
+	parts := []string{}
+	for _, v := range m.values {
+		parts = append(parts, fmt.Sprintf("%d`%v", m.counts[v], v))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
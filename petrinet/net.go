@@ -11,6 +11,8 @@ import (
 	"image/png"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/goccy/go-graphviz"
 )
@@ -18,8 +20,14 @@ import (
 type Net struct {
 	id           string
 	places       []PlaceI
+	placeIdx     map[PlaceI]int // position of each place in 'places'
 	transitions  []TransitionI
-	animation    bool // enable/disable animation recording
+	chooser      Chooser                       // policy choosing transition to fire (nil: first enabled)
+	chooserMu    sync.Mutex                    // policy is shared by transition goroutines
+	tick         uint64                        // logical clock of enabling times
+	clock        Clock                         // time source of timed nets
+	timers       map[*Transition]time.Duration // enabling time of transitions (timed step engine)
+	animation    bool                          // enable/disable animation recording
 	animationSem chan bool
 	frames       []frame // animation sequence
 }
@@ -30,12 +38,13 @@ type frame struct {
 }
 
 func NewNet(id string) *Net {
-	net := Net{id: id, animationSem: make(chan bool, 1)}
+	net := Net{id: id, placeIdx: map[PlaceI]int{}, clock: NewRealClock(), animationSem: make(chan bool, 1)}
 	net.animationSem <- true
 	return &net
 }
 func (n *Net) NewPlace(id string) PlaceI {
 	p := newPlace(id)
+	n.placeIdx[p] = len(n.places)
 	n.places = append(n.places, p)
 	return p
 }
@@ -44,14 +53,23 @@ func (n *Net) NewTransition(id string) TransitionI {
 	n.transitions = append(n.transitions, t)
 	return t
 }
-func (n *Net) Start() {
+
+// Start a goroutine for every transition: a running transition fires as soon
+// as it is enabled, or at its earliest firing time (see 'SetInterval()').
+// Running nets wait in real time, so a net with a virtual clock can't be
+// started (use 'TimedStep()' instead).
+func (n *Net) Start() error {
+	if _, virtual := n.clock.(*virtualClock); virtual {
+		return fmt.Errorf("Start() failed for [%s]: virtual clock needs 'TimedStep()'", n.id)
+	}
 	// initial frame
-	dot := n.buildDot(nil)
+	dot := n.buildDot()
 	n.addAnimationFrame([]frame{{dot, 200}})
 
 	for _, t := range n.transitions {
 		t.start()
 	}
+	return NoError
 }
 func (n *Net) Stop() {
 	for _, t := range n.transitions {
@@ -59,8 +77,8 @@ func (n *Net) Stop() {
 	}
 }
 
-// build net graph as graphviz dot string
-func (n *Net) buildDot(t0 TransitionI) string {
+// build net graph as graphviz dot string, highlighting 'fired' transitions
+func (n *Net) buildDot(fired ...TransitionI) string {
 	places := ""
 	// Places
 	for _, p := range n.places {
@@ -69,8 +87,10 @@ func (n *Net) buildDot(t0 TransitionI) string {
 			toks = "\n●" + fmt.Sprintf("%d", p.Tokens())
 		}
 		color := ""
-		if t0 != nil && t0.isConnectedToPlace(p) {
-			color = ", style=filled, fillcolor=orange"
+		for _, t0 := range fired {
+			if t0.isConnectedToPlace(p) {
+				color = ", style=filled, fillcolor=orange"
+			}
 		}
 		places += "P_" + p.Id() + " [label=\"" + p.Id() + toks + "\"" + color + "]\n"
 	}
@@ -80,8 +100,10 @@ func (n *Net) buildDot(t0 TransitionI) string {
 		tp := t.(*Transition)
 		// Transitions
 		color := ""
-		if t == t0 {
-			color = ", style=filled, fillcolor=lightblue"
+		for _, t0 := range fired {
+			if t == t0 {
+				color = ", style=filled, fillcolor=lightblue"
+			}
 		}
 		transitions += "T_" + t.Id() + " [label=\"" + t.Id() + "\"" + color + "]\n"
 		// Relationships
@@ -135,7 +157,7 @@ digraph PetriNet {
 
 // Save Petri Net as PNG
 func (n *Net) SavePng(filename string) error {
-	dot := n.buildDot(nil)
+	dot := n.buildDot()
 	//logger.Println(dot)
 
 	img := dot2image(dot, map[string]string{"%LEGEND%": ""})
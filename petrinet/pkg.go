@@ -1,3 +1,21 @@
+/*
+Package petrinet runs Petri nets, every Transition being a goroutine (see
+'Net.Start()'), and analyzes them offline without touching Place tokens.
+
+Exploration limit: analysis methods with a 'limit' parameter stop after
+'limit' markings (tree nodes, or events for unfoldings) when 'limit' > 0.
+In that case the partial result built so far is returned together with a
+non nil error, and it only describes the explored part of the state space.
+Methods without a 'limit' parameter use 'AnalysisLimit'.
+
+Temporal properties ('Net.CheckCTL()', 'Net.CheckLTL()') are written with:
+  - atoms: true, false, '<place id> <op> <int>' (op: <, <=, =, ==, !=, >=, >),
+    'Enabled(<transition id>)' or 'Enabled<transition id>';
+  - boolean operators: !, &, |, ->;
+  - CTL operators: EX, AX, EF, AF, EG, AG, E[f U g], A[f U g];
+  - LTL operators: X, F, G, U, R.
+For example 'AG(Pa <= 2)' or 'AF EnabledT1'.
+*/
 package petrinet
 
 import (
@@ -20,3 +38,7 @@ var ctx = context.TODO()
 
 // constants
 var NoError error = nil
+
+// Max number of markings (or tree nodes) explored by analysis methods
+// without an explicit limit, like 'Net.Bounds()'
+var AnalysisLimit = 1000000
@@ -3,13 +3,14 @@ package petrinet
 import (
 	"fmt"
 
+	"github.com/golang-collections/collections/set"
 	"golang.org/x/sync/semaphore"
 )
 
 type PlaceI interface {
 	String() string
 	Id() string
-	// Concurrent-safe add tokens operation
+	// Concurrent-safe add tokens operation (false, leaving tokens unchanged, if capacity would be exceeded)
 	AddTokens(toks int) bool
 	// Connect Place -> Transition with a weighted Arc
 	ConnectTo(t TransitionI, weight int)
@@ -20,6 +21,10 @@ type PlaceI interface {
 	SetAlertOnchange()
 	// Blocks execution waiting for alert
 	WaitForAlert()
+	// Limit tokens in place: transitions exceeding it are not enabled
+	SetCapacity(k int)
+	// Maximum number of tokens (-1 if unbounded)
+	Capacity() int
 
 	addIn(a ArcI)
 	addOut(a ArcI)
@@ -35,6 +40,7 @@ type PlaceI interface {
 type Place struct {
 	id             string
 	toks           int
+	capacity       int // maximum tokens (undef if unbounded)
 	sem            *semaphore.Weighted
 	arcs_in        []ArcI
 	arcs_out       []ArcI
@@ -43,7 +49,7 @@ type Place struct {
 }
 
 func newPlace(id string) *Place {
-	return &Place{id: id, sem: semaphore.NewWeighted(1), alert: make(chan bool, 1)}
+	return &Place{id: id, capacity: undef, sem: semaphore.NewWeighted(1), alert: make(chan bool, 1)}
 }
 func (p *Place) String() string {
 	s := fmt.Sprintf("Place: ID [%s] Tokens [%d]", p.Id(), p.Tokens())
@@ -59,6 +65,15 @@ func (p *Place) Id() string {
 func (p *Place) Tokens() int {
 	return p.toks
 }
+func (p *Place) SetCapacity(k int) {
+	if k < 0 || p.toks > k {
+		logger.Panicf("Place [%s] cannot have capacity %d", p.id, k)
+	}
+	p.capacity = k
+}
+func (p *Place) Capacity() int {
+	return p.capacity
+}
 func (p *Place) SetAlertFunc(f func(PlaceI) bool) {
 	p.alert_onchange = f
 }
@@ -85,6 +100,12 @@ func (p *Place) notifyTransitions() {
 	for _, a := range p.arcs_out {
 		a.Notify()
 	}
+	// guards can read output places too, and a full place blocks its input transitions
+	for _, a := range p.arcs_in {
+		if p.capacity != undef || a.Transition().(*Transition).guard != nil {
+			a.Transition().notifyReadiness()
+		}
+	}
 }
 func (p *Place) generateAlert() {
 	// non-blocking send
@@ -100,6 +121,10 @@ func (p *Place) addTokensNoLock(toks int) bool {
 		logger.Panicf("Place [%s] cannot contain negative value for tokens", p.id)
 		return false
 	}
+	if p.capacity != undef && new_tokens > p.capacity {
+		logger.Panicf("Place [%s] cannot contain more than %d tokens", p.id, p.capacity)
+		return false
+	}
 	// update tokens
 	p.toks = new_tokens
 	if new_tokens != old_tokens { // change in tokens
@@ -114,7 +139,16 @@ func (p *Place) AddTokens(toks int) bool {
 	p.lock()
 	defer p.unlock()
 
-	return p.addTokensNoLock(toks)
+	if p.capacity != undef && p.toks+toks > p.capacity {
+		return false // marking is unchanged
+	}
+	if !p.addTokensNoLock(toks) {
+		return false
+	}
+	held := set.New()
+	held.Insert(p)
+	refreshEnabling([]PlaceI{p}, held)
+	return true
 }
 func (p *Place) addIn(a ArcI) {
 	p.arcs_in = append(p.arcs_in, a)
@@ -0,0 +1,81 @@
+package petrinet
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// Choose among enabled transitions with the highest priority (see
+// 'SetPriority()'), breaking ties with 'tieBreak' (first one if nil)
+type priorityChooser struct {
+	tieBreak Chooser
+}
+
+func NewPriorityChooser(tieBreak Chooser) Chooser {
+	if tieBreak == nil {
+		tieBreak = NewFirstChooser()
+	}
+	return &priorityChooser{tieBreak: tieBreak}
+}
+func (c *priorityChooser) Choose(n *Net, enabled []TransitionI) TransitionI {
+	return c.tieBreak.Choose(n, highestPriority(enabled))
+}
+
+// Transitions with the highest priority (in the same order)
+func highestPriority(ts []TransitionI) []TransitionI {
+	highest := []TransitionI{}
+	for _, t := range ts {
+		if len(highest) > 0 && t.Priority() < highest[0].Priority() {
+			continue
+		}
+		if len(highest) > 0 && t.Priority() > highest[0].Priority() {
+			highest = highest[:0]
+		}
+		highest = append(highest, t)
+	}
+	return highest
+}
+
+// Choose at random with probability proportional to transition weight (see
+// 'SetWeight()'), with a reproducible sequence for a given seed
+type weightedChooser struct {
+	rnd *rand.Rand
+}
+
+func NewWeightedChooser(seed int64) Chooser {
+	return &weightedChooser{rnd: rand.New(rand.NewSource(seed))}
+}
+func (c *weightedChooser) Choose(n *Net, enabled []TransitionI) TransitionI {
+	return pickWeighted(c.rnd, enabled, func(t *Transition) float64 { return t.weight })
+}
+
+// Pick at random with probability proportional to 'weight' of transitions
+func pickWeighted(rnd *rand.Rand, ts []TransitionI, weight func(*Transition) float64) *Transition {
+	total := 0.0
+	for _, t := range ts {
+		total += weight(t.(*Transition))
+	}
+	x := rnd.Float64() * total
+	for _, t := range ts {
+		if x -= weight(t.(*Transition)); x < 0 {
+			return t.(*Transition)
+		}
+	}
+	return ts[len(ts)-1].(*Transition)
+}
+
+// Choose the transition enabled for the longest time (first in net order on ties)
+type fifoChooser struct{}
+
+func NewFIFOChooser() Chooser {
+	return &fifoChooser{}
+}
+func (c *fifoChooser) Choose(n *Net, enabled []TransitionI) TransitionI {
+	oldest := enabled[0].(*Transition)
+	for _, ti := range enabled[1:] {
+		if t := ti.(*Transition); atomic.LoadUint64(&t.enabledSince) < atomic.LoadUint64(&oldest.enabledSince) {
+			oldest = t
+		}
+	}
+	return oldest
+}
@@ -0,0 +1,166 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// (P) ◄──► [T<i>] for every id
+func buildSelfLoops(ids ...string) *Net {
+	net := NewNet("TestNet")
+	p := net.NewPlace("P")
+	for _, id := range ids {
+		t := net.NewTransition(id)
+		p.ConnectTo(t, 1)
+		t.ConnectTo(p, 1)
+	}
+	p.AddTokens(1)
+	return net
+}
+
+func TestPriorityChooser(test *testing.T) {
+	net := buildSelfLoops("T1", "T2", "T3")
+	net.transitions[1].SetPriority(2)
+	net.transitions[2].SetPriority(2)
+	net.SetChooser(NewPriorityChooser(nil))
+	assert.Equal(test, []string{"T2", "T2", "T2"}, runSteps(net, 3))
+
+	net.SetChooser(NewPriorityChooser(NewRoundRobinChooser()))
+	assert.Equal(test, []string{"T2", "T3", "T2"}, runSteps(net, 3))
+}
+
+func TestWeightedChooser(test *testing.T) {
+	net := buildSelfLoops("T1", "T2")
+	net.transitions[0].SetWeight(9)
+	net.SetChooser(NewWeightedChooser(1))
+	trace := runSteps(net, 1000)
+	count := 0
+	for _, id := range trace {
+		if id == "T1" {
+			count++
+		}
+	}
+	assert.InDelta(test, 900, count, 50)
+
+	// same seed, same run
+	net.SetChooser(NewWeightedChooser(1))
+	assert.Equal(test, trace, runSteps(net, 1000))
+}
+
+func TestFIFOChooser(test *testing.T) {
+	/* build net:
+
+	(P) ◄──► [T1]
+	(P) ◄──► [T2]
+	(P) ◄──► [T3]
+
+	*/
+	net := buildSelfLoops("T1", "T2", "T3")
+	net.SetChooser(NewFIFOChooser())
+	// fired transition is enabled again after the others
+	assert.Equal(test, []string{"T1", "T2", "T3", "T1", "T2"}, runSteps(net, 5))
+}
+
+func TestPolicyRunningNet(test *testing.T) {
+	disableLogger()
+	/* build net (On and Off compete for In):
+
+	(In)──►[On]───►(Out)
+	  │
+	  └───►[Off]──►(Lost)
+
+	*/
+	const N = 20
+	net := NewNet("TestNet")
+	in := net.NewPlace("In")
+	out := net.NewPlace("Out")
+	lost := net.NewPlace("Lost")
+	on := net.NewTransition("On")
+	off := net.NewTransition("Off")
+	in.ConnectTo(on, 1)
+	on.ConnectTo(out, 1)
+	in.ConnectTo(off, 1)
+	off.ConnectTo(lost, 1)
+	on.SetPriority(1)
+	net.SetChooser(NewPriorityChooser(nil))
+	out.SetAlertOnchange()
+
+	net.Start()
+	for k := 0; k < N; k++ {
+		in.AddTokens(1)
+		out.WaitForAlert()
+	}
+	net.Stop()
+
+	assert.Equal(test, N, out.Tokens())
+	assert.Equal(test, 0, lost.Tokens())
+}
+
+func TestPolicyConflictCluster(test *testing.T) {
+	disableLogger()
+	/* build net (A > B > C, A and C don't share places):
+
+	(PAB)──►[A]──►(OA)
+	  │
+	  └────►[B]──►(OB)
+	  ┌─────┘
+	(PBC)──►[C]──►(OC)
+
+	*/
+	net := NewNet("TestNet")
+	pab := net.NewPlace("PAB")
+	pbc := net.NewPlace("PBC")
+	outs := map[string]PlaceI{}
+	for k, id := range []string{"A", "B", "C"} {
+		t := net.NewTransition(id)
+		outs[id] = net.NewPlace("O" + id)
+		t.ConnectTo(outs[id], 1)
+		t.SetPriority(3 - k)
+	}
+	a, b, c := net.transitions[0], net.transitions[1], net.transitions[2]
+	pab.ConnectTo(a, 1)
+	pab.ConnectTo(b, 1)
+	pbc.ConnectTo(b, 1)
+	pbc.ConnectTo(c, 1)
+	assert.Equal(test, []*Transition{c.(*Transition)}, net.cluster(c.(*Transition), []TransitionI{a, c}))
+	assert.Equal(test, 3, len(conflictCluster(c.(*Transition))))
+
+	pab.AddTokens(1)
+	pbc.AddTokens(1)
+	net.SetChooser(NewPriorityChooser(nil))
+	outs["C"].SetAlertOnchange()
+	// whichever transition is notified first, A wins over B, which lets C fire
+	net.Start()
+	outs["C"].WaitForAlert()
+	net.Stop()
+
+	assert.Equal(test, 1, outs["A"].Tokens())
+	assert.Equal(test, 0, outs["B"].Tokens())
+	assert.Equal(test, 1, outs["C"].Tokens())
+}
+
+func TestFIFOChooserEnablingTime(test *testing.T) {
+	/* build net:
+
+	(P1)──►[T1]◄──(P2)
+	(P3)──►[T2]
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p1.ConnectTo(t1, 1)
+	p2.ConnectTo(t1, 1)
+	p3.ConnectTo(t2, 1)
+	net.SetChooser(NewFIFOChooser())
+
+	// T1 is enabled only when P2 gets its token, after T2
+	p1.AddTokens(1)
+	p3.AddTokens(1)
+	p2.AddTokens(1)
+	assert.Equal(test, []string{"T2", "T1"}, runSteps(net, 2))
+}
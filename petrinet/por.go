@@ -0,0 +1,192 @@
+package petrinet
+
+// Options of state space exploration
+type ExploreOptions struct {
+	Reduction bool     // explore with stubborn set reduction
+	Visible   []string // ids of places read by safety properties (used by reduction)
+}
+
+/*
+Enable stubborn set (partial-order) reduction: in every marking only a subset
+of enabled transitions, independent from the others, is fired.
+Reduced graph has the same dead markings of the full one, so the option can
+be given to 'ReachabilityGraph()' and 'Deadlocks()'.
+When 'visible' places are given, every marking of these places reachable in
+the full graph is also reachable in the reduced one: 'CheckCTL()' accepts the
+option for 'AG f' and 'EF f' properties, with 'f' only reading place tokens
+(places of 'f' are added to 'visible').
+*/
+func WithReduction(visible ...string) func(*ExploreOptions) {
+	return func(opts *ExploreOptions) {
+		opts.Reduction = true
+		opts.Visible = visible
+	}
+}
+
+// Stubborn sets computation, based on arc structure
+type stubbornSets struct {
+	net      *Net
+	index    map[*Transition]int
+	effect   []map[int]int  // transition -> place -> tokens added by firing
+	access   []map[int]bool // transition -> places connected by any arc
+	visible  []bool         // transition changes a visible place
+	proviso  bool           // avoid ignoring visible transitions
+	increase [][]int        // place -> transitions adding tokens
+	decrease [][]int        // place -> transitions removing tokens
+}
+
+func (n *Net) newStubbornSets(visible []string) *stubbornSets {
+	st := &stubbornSets{
+		net:      n,
+		index:    map[*Transition]int{},
+		effect:   make([]map[int]int, len(n.transitions)),
+		access:   make([]map[int]bool, len(n.transitions)),
+		visible:  make([]bool, len(n.transitions)),
+		proviso:  len(visible) > 0,
+		increase: make([][]int, len(n.places)),
+		decrease: make([][]int, len(n.places)),
+	}
+	isVisible := map[string]bool{}
+	for _, id := range visible {
+		isVisible[id] = true
+	}
+	for j, ti := range n.transitions {
+		t := ti.(*Transition)
+		st.index[t] = j
+		st.effect[j] = map[int]int{}
+		st.access[j] = map[int]bool{}
+		for _, arc := range t.arcs_in {
+			i := n.placeIdx[arc.Place()]
+			st.effect[j][i] -= arcWeight(arc)
+			st.access[j][i] = true
+		}
+		for _, arc := range t.arcs_out {
+			i := n.placeIdx[arc.Place()]
+			st.effect[j][i] += arcWeight(arc)
+			st.access[j][i] = true
+		}
+		for i, delta := range st.effect[j] {
+			if delta > 0 {
+				st.increase[i] = append(st.increase[i], j)
+			}
+			if delta < 0 {
+				st.decrease[i] = append(st.decrease[i], j)
+			}
+			if delta != 0 && isVisible[n.places[i].Id()] {
+				st.visible[j] = true
+			}
+		}
+	}
+	return st
+}
+
+// Transitions to fire in state 's' of graph under construction
+func (st *stubbornSets) reduce(g *ReachabilityGraph, s state, enabled []*Transition) []*Transition {
+	if len(enabled) <= 1 {
+		return enabled
+	}
+	// smallest stubborn set, trying every enabled transition as seed
+	var best []*Transition
+	for _, seed := range enabled {
+		reduced := st.closure(s, seed)
+		if best == nil || len(reduced) < len(best) {
+			best = reduced
+		}
+	}
+	if st.proviso && len(best) < len(enabled) {
+		// a cycle in the reduced graph could postpone visible transitions forever:
+		// fully expand markings reaching an already explored marking
+		for _, t := range best {
+			if _, found := g.index[st.net.fireAt(t, s).key()]; found {
+				return enabled
+			}
+		}
+	}
+	return best
+}
+
+// Stubborn set containing 'seed': returns its enabled transitions (in net order)
+func (st *stubbornSets) closure(s state, seed *Transition) []*Transition {
+	n := st.net
+	in := make([]bool, len(n.transitions))
+	queue := []int{st.index[seed]}
+	in[st.index[seed]] = true
+	add := func(js ...int) {
+		for _, j := range js {
+			if !in[j] {
+				in[j] = true
+				queue = append(queue, j)
+			}
+		}
+	}
+	visibleAdded := false
+	for len(queue) > 0 {
+		j := queue[0]
+		queue = queue[1:]
+		t := n.transitions[j].(*Transition)
+		if n.isEnabledAt(t, s) {
+			// transitions not commuting with 't'
+			for k := range n.transitions {
+				if st.dependent(j, k) {
+					add(k)
+				}
+			}
+			if st.visible[j] && !visibleAdded {
+				visibleAdded = true
+				for k, v := range st.visible {
+					if v {
+						add(k)
+					}
+				}
+			}
+			continue
+		}
+		// disabled: transitions that can enable it through the first unsatisfied arc
+		blocked := false
+		for _, arc := range t.arcs_in {
+			i := n.placeIdx[arc.Place()]
+			if arcEnabledBy(arc, s[i]) {
+				continue
+			}
+			if e, ok := arc.(*EnableArc); ok && e.high != undef && s[i] > e.high {
+				add(st.decrease[i]...)
+			} else {
+				add(st.increase[i]...)
+			}
+			blocked = true
+			break
+		}
+		if p := t.exceedsCapacity(n.tokensAt(s)); !blocked && p != nil {
+			// full output place: transitions that can drain it
+			add(st.decrease[n.placeIdx[p]]...)
+			blocked = true
+		}
+		if !blocked {
+			// disabled by guard: transitions changing any connected place
+			for i := range st.access[j] {
+				add(st.increase[i]...)
+				add(st.decrease[i]...)
+			}
+		}
+	}
+	reduced := []*Transition{}
+	for j, ti := range n.transitions {
+		if t := ti.(*Transition); in[j] && n.isEnabledAt(t, s) {
+			reduced = append(reduced, t)
+		}
+	}
+	return reduced
+}
+
+// Test if transitions 'j' and 'k' share a place changed by one of them
+func (st *stubbornSets) dependent(j, k int) bool {
+	if j == k {
+		return false
+	}
+	for i := range st.access[j] {
+		if st.access[k][i] && (st.effect[j][i] != 0 || st.effect[k][i] != 0) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package petrinet
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// N independent transitions: (P_i)──►[T_i]──►(Q_i)
+func buildIndependentNet(N int) *Net {
+	net := NewNet("TestNet")
+	for i := 0; i < N; i++ {
+		p := net.NewPlace(fmt.Sprintf("P%d", i))
+		q := net.NewPlace(fmt.Sprintf("Q%d", i))
+		t := net.NewTransition(fmt.Sprintf("T%d", i))
+		p.ConnectTo(t, 1)
+		t.ConnectTo(q, 1)
+		p.AddTokens(1)
+	}
+	return net
+}
+
+func TestReductionPreservesDeadlocks(test *testing.T) {
+	const N = 10
+	net := buildIndependentNet(N)
+
+	full, err := net.ReachabilityGraph(0)
+	assert.NoError(test, err)
+	assert.Equal(test, powInt(2, N), len(full.Nodes))
+
+	reduced, err := net.ReachabilityGraph(0, WithReduction())
+	assert.NoError(test, err)
+	assert.Equal(test, N+1, len(reduced.Nodes))
+
+	// same (single) dead marking
+	last := reduced.Nodes[len(reduced.Nodes)-1]
+	assert.Empty(test, last.Out)
+	_, found := full.Find(last.Marking)
+	assert.True(test, found)
+}
+
+func TestReductionWithConflicts(test *testing.T) {
+	/* build net:
+
+	(P)──►[T1]──►(Q1)
+	 │
+	 └───►[T2]──►(Q2)     + N independent transitions
+
+	*/
+	const N = 4
+	net := buildIndependentNet(N)
+	p := net.NewPlace("P")
+	q1 := net.NewPlace("Q1")
+	q2 := net.NewPlace("Q2")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p.ConnectTo(t1, 1)
+	p.ConnectTo(t2, 1)
+	t1.ConnectTo(q1, 1)
+	t2.ConnectTo(q2, 1)
+	p.AddTokens(1)
+
+	full, err := net.ReachabilityGraph(0)
+	assert.NoError(test, err)
+	reduced, err := net.ReachabilityGraph(0, WithReduction("Q1", "Q2"))
+	assert.NoError(test, err)
+	assert.Less(test, len(reduced.Nodes), len(full.Nodes))
+	// both choices are kept
+	for _, m := range []Marking{{"Q1": 1}, {"Q2": 1}} {
+		foundQ := false
+		for _, node := range reduced.Nodes {
+			foundQ = foundQ || (node.Marking["Q1"] == m["Q1"] && node.Marking["Q2"] == m["Q2"])
+		}
+		assert.True(test, foundQ)
+	}
+}
+
+func TestReductionDeadlocks(test *testing.T) {
+	/* build net (as in TestConcurrentTriggering):
+
+	(P0)──┬──►[T0..T39]──►(PEnd)
+	(P)───┘
+
+	*/
+	const TRANS = 40
+	net := NewNet("TestNet")
+	p0 := net.NewPlace("P0")
+	p := net.NewPlace("P")
+	pEnd := net.NewPlace("PEnd")
+	for i := 0; i < TRANS; i++ {
+		t := net.NewTransition(fmt.Sprintf("T%d", i))
+		p.ConnectTo(t, 1)
+		p0.ConnectTo(t, 1)
+		t.ConnectTo(pEnd, 1)
+	}
+	p0.AddTokens(4)
+	p.AddTokens(2)
+
+	full, err := net.Deadlocks(0)
+	assert.NoError(test, err)
+	reduced, err := net.Deadlocks(0, WithReduction())
+	assert.NoError(test, err)
+	assert.Equal(test, full.Deadlocks, reduced.Deadlocks)
+	assert.Equal(test, Marking{"P0": 2, "P": 0, "PEnd": 2}, reduced.Deadlocks[0].Marking)
+	g, err := net.ReachabilityGraph(0, WithReduction())
+	assert.NoError(test, err)
+	assert.Equal(test, 3, len(g.Nodes))
+
+	// 40 independent transitions: 2^40 markings, 41 once reduced
+	net = buildIndependentNet(TRANS)
+	g, err = net.ReachabilityGraph(0, WithReduction())
+	assert.NoError(test, err)
+	assert.Equal(test, TRANS+1, len(g.Nodes))
+	report, err := net.Deadlocks(0, WithReduction())
+	assert.NoError(test, err)
+	assert.Equal(test, 1, len(report.Deadlocks))
+	assert.Equal(test, TRANS, len(report.Deadlocks[0].Sequence))
+	assert.Empty(test, report.DeadTransitions)
+	report, err = net.Deadlocks(1000)
+	assert.Error(test, err)
+	assert.True(test, report.Partial)
+
+	_, err = net.CheckCTL("AG EnabledT0", WithReduction())
+	assert.Error(test, err)
+	r, err := net.CheckCTL("EF(Q0 = 1 & Q39 = 1)", WithReduction())
+	assert.NoError(test, err)
+	assert.True(test, r.Holds)
+	_, err = net.CheckCTL("AF(Q0 = 1)", WithReduction())
+	assert.Error(test, err)
+}
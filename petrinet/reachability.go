@@ -0,0 +1,102 @@
+package petrinet
+
+import "fmt"
+
+/*
+Reachability graph: every marking reachable from the current marking
+of the net (nodes) and the transition firings linking them (edges).
+Node 0 is always the initial marking.
+*/
+type ReachabilityGraph struct {
+	Nodes  []*ReachabilityNode
+	Edges  []*ReachabilityEdge
+	net    *Net
+	states []state        // node id -> state
+	index  map[string]int // state key -> node id
+}
+
+type ReachabilityNode struct {
+	Id      int
+	Marking Marking
+	Out     []*ReachabilityEdge // outgoing edges
+}
+
+type ReachabilityEdge struct {
+	From       int    // source node id
+	To         int    // target node id
+	Transition string // fired transition id
+}
+
+func (e *ReachabilityEdge) String() string {
+	return fmt.Sprintf("%d -[%s]-> %d", e.From, e.Transition, e.To)
+}
+
+// Explore (breadth-first) every marking reachable from the current marking.
+// Transitions are fired in a simulated way: goroutines are not started and
+// places tokens are not changed.
+// Exploration is bounded by 'limit' markings (see package doc).
+// Exploration can be customized with options, e.g. 'WithReduction()'.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) ReachabilityGraph(limit int, options ...func(*ExploreOptions)) (*ReachabilityGraph, error) {
+	opts := ExploreOptions{}
+	for _, f := range options {
+		f(&opts)
+	}
+	var reducer *stubbornSets
+	if opts.Reduction {
+		reducer = n.newStubbornSets(opts.Visible)
+	}
+	g := &ReachabilityGraph{net: n, index: map[string]int{}}
+	g.addNode(n.currentState())
+
+	for next := 0; next < len(g.states); next++ {
+		s := g.states[next]
+		enabled := []*Transition{}
+		for _, ti := range n.transitions {
+			if t := ti.(*Transition); n.isEnabledAt(t, s) {
+				enabled = append(enabled, t)
+			}
+		}
+		if reducer != nil {
+			enabled = reducer.reduce(g, s, enabled)
+		}
+		for _, t := range enabled {
+			succ := n.fireAt(t, s)
+			to, found := g.index[succ.key()]
+			if !found {
+				if limit > 0 && len(g.states) >= limit {
+					return g, fmt.Errorf("ReachabilityGraph() for [%s] stopped after %d markings", n.id, limit)
+				}
+				to = g.addNode(succ)
+			}
+			g.addEdge(next, to, t.Id())
+		}
+	}
+	return g, NoError
+}
+
+func (g *ReachabilityGraph) addNode(s state) int {
+	id := len(g.states)
+	g.states = append(g.states, s)
+	g.index[s.key()] = id
+	g.Nodes = append(g.Nodes, &ReachabilityNode{Id: id, Marking: g.net.toMarking(s)})
+	return id
+}
+func (g *ReachabilityGraph) addEdge(from, to int, transition string) {
+	e := &ReachabilityEdge{From: from, To: to, Transition: transition}
+	g.Edges = append(g.Edges, e)
+	g.Nodes[from].Out = append(g.Nodes[from].Out, e)
+}
+
+// Find node with given marking. Places missing in marking are considered empty.
+func (g *ReachabilityGraph) Find(m Marking) (*ReachabilityNode, bool) {
+	s := make(state, len(g.net.places))
+	for i, p := range g.net.places {
+		s[i] = m[p.Id()]
+	}
+	id, found := g.index[s.key()]
+	if !found {
+		return nil, false
+	}
+	return g.Nodes[id], true
+}
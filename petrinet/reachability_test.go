@@ -0,0 +1,59 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReachabilityGraph(test *testing.T) {
+	/* build net:
+
+	(P1)──2──►[T1]──►(P2)──►[T2]
+	            ●
+	(P3)──<0>───┘
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p1.ConnectTo(t1, 2)
+	t1.ConnectTo(p2, 1)
+	t1.InhibitedBy(p3)
+	p2.ConnectTo(t2, 1)
+
+	p1.AddTokens(4)
+	g, err := net.ReachabilityGraph(0)
+	assert.NoError(test, err)
+	// (4,0) (2,1) (0,2) (2,0) (0,1) (0,0)
+	assert.Equal(test, 6, len(g.Nodes))
+	assert.Equal(test, Marking{"P1": 4, "P2": 0, "P3": 0}, g.Nodes[0].Marking)
+	node, found := g.Find(Marking{"P2": 2})
+	assert.True(test, found)
+	assert.Equal(test, 1, len(node.Out))
+	assert.Equal(test, "T2", node.Out[0].Transition)
+	// places tokens are untouched
+	assert.Equal(test, 4, p1.Tokens())
+
+	// inhibitor arc disables every firing
+	p3.AddTokens(1)
+	g, err = net.ReachabilityGraph(0)
+	assert.NoError(test, err)
+	assert.Equal(test, 1, len(g.Nodes))
+	assert.Equal(test, 0, len(g.Edges))
+}
+
+func TestReachabilityGraphLimit(test *testing.T) {
+	// generator transition: infinite state space
+	net := NewNet("TestNet")
+	t := net.NewTransition("Gen")
+	p := net.NewPlace("P")
+	t.ConnectTo(p, 1)
+
+	g, err := net.ReachabilityGraph(10)
+	assert.Error(test, err)
+	assert.Equal(test, 10, len(g.Nodes))
+}
@@ -0,0 +1,487 @@
+package petrinet
+
+import (
+	"fmt"
+)
+
+// Behaviour preserving reduction rules (Murata)
+type ReductionRule int
+
+const (
+	FuseSeriesPlaces ReductionRule = iota
+	FuseSeriesTransitions
+	FuseParallelPlaces
+	FuseParallelTransitions
+	EliminateSelfLoopPlaces
+	EliminateSelfLoopTransitions
+)
+
+// Options of net reduction
+type ReduceOptions struct {
+	Rules []ReductionRule // rules to apply (all by default)
+	Keep  []string        // ids of places and transitions never removed or fused
+}
+
+// Apply only the given reduction rules
+func WithRules(rules ...ReductionRule) func(*ReduceOptions) {
+	return func(opts *ReduceOptions) {
+		opts.Rules = rules
+	}
+}
+
+// Never remove or fuse places and transitions with given ids
+// (e.g. places read by a property to check on the reduced net)
+func Keeping(ids ...string) func(*ReduceOptions) {
+	return func(opts *ReduceOptions) {
+		opts.Keep = append(opts.Keep, ids...)
+	}
+}
+
+/*
+Reduced net and mapping back to the original one.
+Reduction rules preserve liveness, boundedness and safeness, so these
+properties can be checked on the (smaller) reduced net and translated back.
+*/
+type Reduction struct {
+	Net                *Net
+	Places             map[string][]string   // reduced place -> original places (fused in series or parallel)
+	Transitions        map[string][]string   // reduced transition -> original transitions fused in series (firing order)
+	Alternatives       map[string][][]string // reduced transition -> original firing sequences removed as parallel
+	SelfLoopPlaces     map[string]int        // original place removed as self-loop -> its (constant) tokens
+	RemovedPlaces      []string              // original places removed fusing transitions in series
+	RemovedTransitions []string              // original transitions removed (series places fusion, self-loops)
+}
+
+// Working copy of a place during reduction
+type rplace struct {
+	id       string
+	ids      []string // original places
+	tokens   int
+	capacity int
+	fixed    bool // kept, read by an EnableArc or by a guard, or with a capacity
+	alive    bool
+}
+
+// Working copy of a transition during reduction
+type rtrans struct {
+	id     string
+	seq    []string // original transitions, in firing order
+	alts   [][]string
+	in     map[int]int // place -> arc weight
+	out    map[int]int // place -> arc weight
+	enable []*EnableArc
+	guard  func(Marking) bool
+	fixed  bool // kept, with EnableArcs or with a guard
+	alive  bool
+}
+
+type reducer struct {
+	places      []*rplace
+	transitions []*rtrans
+	result      *Reduction
+}
+
+// Reduce the net applying reduction rules until none applies, starting from
+// the current marking. Places and transitions connected to EnableArcs,
+// guarded transitions with their places, places with a capacity and their
+// input transitions are never removed nor fused.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) Reduce(options ...func(*ReduceOptions)) (*Reduction, error) {
+	opts := ReduceOptions{Rules: []ReductionRule{
+		FuseSeriesPlaces, FuseSeriesTransitions,
+		FuseParallelPlaces, FuseParallelTransitions,
+		EliminateSelfLoopPlaces, EliminateSelfLoopTransitions,
+	}}
+	for _, f := range options {
+		f(&opts)
+	}
+	keep := map[string]bool{}
+	for _, id := range opts.Keep {
+		keep[id] = true
+	}
+	r := &reducer{result: &Reduction{
+		Places:         map[string][]string{},
+		Transitions:    map[string][]string{},
+		Alternatives:   map[string][][]string{},
+		SelfLoopPlaces: map[string]int{},
+	}}
+	for _, p := range n.places {
+		r.places = append(r.places, &rplace{id: p.Id(), ids: []string{p.Id()}, tokens: p.Tokens(), capacity: p.Capacity(), fixed: keep[p.Id()] || p.Capacity() != undef, alive: true})
+	}
+	for _, ti := range n.transitions {
+		t := ti.(*Transition)
+		rt := &rtrans{id: t.Id(), seq: []string{t.Id()}, in: map[int]int{}, out: map[int]int{}, guard: t.guard, fixed: keep[t.Id()] || t.guard != nil, alive: true}
+		for _, arc := range t.arcs_in {
+			i := n.placeIdx[arc.Place()]
+			if t.guard != nil {
+				r.places[i].fixed = true // read by guard
+			}
+			if e, ok := arc.(*EnableArc); ok {
+				rt.enable = append(rt.enable, e)
+				rt.fixed = true
+				r.places[i].fixed = true
+				continue
+			}
+			rt.in[i] += arcWeight(arc)
+		}
+		for _, arc := range t.arcs_out {
+			i := n.placeIdx[arc.Place()]
+			if t.guard != nil {
+				r.places[i].fixed = true
+			}
+			if arc.Place().Capacity() != undef {
+				rt.fixed = true // blocked by a full place
+			}
+			rt.out[i] += arcWeight(arc)
+		}
+		r.transitions = append(r.transitions, rt)
+	}
+
+	rules := map[ReductionRule]func() bool{
+		FuseSeriesPlaces:             r.fuseSeriesPlaces,
+		FuseSeriesTransitions:        r.fuseSeriesTransitions,
+		FuseParallelPlaces:           r.fuseParallelPlaces,
+		FuseParallelTransitions:      r.fuseParallelTransitions,
+		EliminateSelfLoopPlaces:      r.eliminateSelfLoopPlaces,
+		EliminateSelfLoopTransitions: r.eliminateSelfLoopTransitions,
+	}
+	for _, rule := range opts.Rules {
+		if rules[rule] == nil {
+			return nil, fmt.Errorf("Reduce() failed for [%s]: unknown rule %d", n.id, rule)
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range opts.Rules {
+			for rules[rule]() {
+				changed = true
+			}
+		}
+	}
+	r.build(n.id + "_reduced")
+	return r.result, NoError
+}
+
+// Transitions producing into (pre) and consuming from (post) place 'i'
+func (r *reducer) neighbours(i int) (pre, post []int) {
+	for j, t := range r.transitions {
+		if !t.alive {
+			continue
+		}
+		if t.out[i] > 0 {
+			pre = append(pre, j)
+		}
+		if t.in[i] > 0 {
+			post = append(post, j)
+		}
+	}
+	return pre, post
+}
+
+// p ──►[t]──► q, with 't' the only output of 'p': fuse 'p' and 'q', remove 't'
+func (r *reducer) fuseSeriesPlaces() bool {
+	for _, t := range r.transitions {
+		if !t.alive || t.fixed || len(t.in) != 1 || len(t.out) != 1 {
+			continue
+		}
+		p, q := single(t.in), single(t.out)
+		if p == q || t.in[p] != 1 || t.out[q] != 1 || r.places[p].fixed || r.places[q].fixed {
+			continue
+		}
+		if _, post := r.neighbours(p); len(post) != 1 {
+			continue
+		}
+		t.alive = false
+		r.removed(t)
+		pp, pq := r.places[p], r.places[q]
+		pp.alive = false
+		pq.id = r.placeId(pp.id + "_" + pq.id)
+		pq.ids = append(pp.ids, pq.ids...)
+		pq.tokens += pp.tokens
+		for _, u := range r.transitions {
+			if u.alive && u.out[p] > 0 {
+				u.out[q] += u.out[p]
+				delete(u.out, p)
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// [t1]──►(p)──►[t2], with 'p' unmarked and the only input of 't2': fuse 't1' and 't2', remove 'p'
+func (r *reducer) fuseSeriesTransitions() bool {
+	for i, p := range r.places {
+		if !p.alive || p.fixed || p.tokens > 0 {
+			continue
+		}
+		pre, post := r.neighbours(i)
+		if len(pre) != 1 || len(post) != 1 || pre[0] == post[0] {
+			continue
+		}
+		t1, t2 := r.transitions[pre[0]], r.transitions[post[0]]
+		if t1.fixed || t2.fixed || len(t2.in) != 1 || t1.out[i] != 1 || t2.in[i] != 1 || len(t1.alts)+len(t2.alts) > 0 {
+			continue
+		}
+		p.alive = false
+		r.result.RemovedPlaces = append(r.result.RemovedPlaces, p.ids...)
+		t2.alive = false
+		delete(t1.out, i)
+		for q, w := range t2.out {
+			t1.out[q] += w
+		}
+		t1.id = r.transitionId(t1.id + "_" + t2.id)
+		t1.seq = append(t1.seq, t2.seq...)
+		return true
+	}
+	return false
+}
+
+// places with same inputs, outputs and tokens: remove one of them
+func (r *reducer) fuseParallelPlaces() bool {
+	for i, p := range r.places {
+		if !p.alive || p.fixed {
+			continue
+		}
+		for k, q := range r.places {
+			if k == i || !q.alive || q.fixed || q.tokens != p.tokens {
+				continue
+			}
+			same := true
+			for _, t := range r.transitions {
+				if t.alive && (t.in[i] != t.in[k] || t.out[i] != t.out[k]) {
+					same = false
+					break
+				}
+			}
+			if !same {
+				continue
+			}
+			p.alive = false
+			q.ids = append(q.ids, p.ids...)
+			for _, t := range r.transitions {
+				delete(t.in, i)
+				delete(t.out, i)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// transitions with same inputs and outputs: remove one of them
+func (r *reducer) fuseParallelTransitions() bool {
+	for j, t := range r.transitions {
+		if !t.alive || t.fixed {
+			continue
+		}
+		for k, u := range r.transitions {
+			if k == j || !u.alive || u.fixed || !sameArcs(t.in, u.in) || !sameArcs(t.out, u.out) {
+				continue
+			}
+			t.alive = false
+			u.alts = append(u.alts, t.seq)
+			u.alts = append(u.alts, t.alts...)
+			return true
+		}
+	}
+	return false
+}
+
+// marked place with a single transition both reading and writing it: remove the place
+func (r *reducer) eliminateSelfLoopPlaces() bool {
+	for i, p := range r.places {
+		if !p.alive || p.fixed || p.tokens == 0 {
+			continue
+		}
+		pre, post := r.neighbours(i)
+		if len(pre) != 1 || len(post) != 1 || pre[0] != post[0] {
+			continue
+		}
+		if t := r.transitions[pre[0]]; t.in[i] != 1 || t.out[i] != 1 {
+			continue
+		}
+		p.alive = false
+		for _, id := range p.ids {
+			r.result.SelfLoopPlaces[id] = p.tokens
+		}
+		delete(r.transitions[pre[0]].in, i)
+		delete(r.transitions[pre[0]].out, i)
+		return true
+	}
+	return false
+}
+
+// transition reading and writing a single place: remove the transition
+func (r *reducer) eliminateSelfLoopTransitions() bool {
+	for _, t := range r.transitions {
+		if !t.alive || t.fixed || len(t.in) != 1 || !sameArcs(t.in, t.out) {
+			continue
+		}
+		if p := single(t.in); t.in[p] != 1 {
+			continue
+		}
+		t.alive = false
+		r.removed(t)
+		return true
+	}
+	return false
+}
+
+func (r *reducer) removed(t *rtrans) {
+	r.result.RemovedTransitions = append(r.result.RemovedTransitions, t.seq...)
+	for _, alt := range t.alts {
+		r.result.RemovedTransitions = append(r.result.RemovedTransitions, alt...)
+	}
+}
+
+func single(m map[int]int) int {
+	for k := range m {
+		return k
+	}
+	return -1
+}
+
+func sameArcs(a, b map[int]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, w := range a {
+		if b[k] != w {
+			return false
+		}
+	}
+	return true
+}
+
+// Build reduced net from alive places and transitions
+func (r *reducer) build(id string) {
+	net := NewNet(id)
+	places := make([]PlaceI, len(r.places))
+	for i, p := range r.places {
+		if !p.alive {
+			continue
+		}
+		places[i] = net.NewPlace(p.id)
+		places[i].AddTokens(p.tokens)
+		if p.capacity != undef {
+			places[i].SetCapacity(p.capacity)
+		}
+		r.result.Places[p.id] = p.ids
+	}
+	for _, rt := range r.transitions {
+		if !rt.alive {
+			continue
+		}
+		t := net.NewTransition(rt.id).(*Transition)
+		t.guard = rt.guard
+		for i := range places {
+			if w := rt.in[i]; w > 0 {
+				places[i].ConnectTo(t, w)
+			}
+		}
+		for _, e := range rt.enable {
+			low, high := e.low, e.high
+			t.EnabledBy(places[r.index(e.P.Id())], func(a *EnableArc) {
+				a.low = low
+				a.high = high
+			})
+		}
+		for i := range places {
+			if w := rt.out[i]; w > 0 {
+				t.ConnectTo(places[i], w)
+			}
+		}
+		r.result.Transitions[rt.id] = rt.seq
+		if len(rt.alts) > 0 {
+			r.result.Alternatives[rt.id] = rt.alts
+		}
+	}
+	r.result.Net = net
+}
+
+// Id of a fused place: 'id' unless a place (original or reduced) has it already
+func (r *reducer) placeId(id string) string {
+	used := map[string]bool{}
+	for _, p := range r.places {
+		used[p.id] = true
+		for _, orig := range p.ids {
+			used[orig] = true
+		}
+	}
+	return unusedId(id, used)
+}
+
+// Id of a fused transition: 'id' unless a transition (original or reduced) has it already
+func (r *reducer) transitionId(id string) string {
+	used := map[string]bool{}
+	for _, t := range r.transitions {
+		used[t.id] = true
+		for _, orig := range t.seq {
+			used[orig] = true
+		}
+	}
+	return unusedId(id, used)
+}
+
+// 'id', or 'id' followed by the first free counter ("_2", "_3", ...)
+func unusedId(id string, used map[string]bool) string {
+	unique := id
+	for k := 2; used[unique]; k++ {
+		unique = fmt.Sprintf("%s_%d", id, k)
+	}
+	return unique
+}
+
+// Position of the (never fused) original place
+func (r *reducer) index(id string) int {
+	for i, p := range r.places {
+		if p.id == id {
+			return i
+		}
+	}
+	logger.Panicf("Place [%s] not found in reduced net", id)
+	return -1
+}
+
+// Translate a firing sequence of the reduced net into one of the original net
+func (red *Reduction) TranslateSequence(seq []string) []string {
+	orig := []string{}
+	for _, id := range seq {
+		orig = append(orig, red.Transitions[id]...)
+	}
+	return orig
+}
+
+// Translate bounds of the reduced net (see 'Bounds()') into bounds of the
+// original places. Bounds of places fused in series are upper bounds;
+// removed places are missing.
+func (red *Reduction) TranslateBounds(bounds Marking) Marking {
+	orig := Marking{}
+	for id, b := range bounds {
+		for _, p := range red.Places[id] {
+			orig[p] = b
+		}
+	}
+	for p, toks := range red.SelfLoopPlaces {
+		orig[p] = toks
+	}
+	return orig
+}
+
+// Translate liveness of reduced transitions (see 'Liveness()') to the
+// original transitions they stand for. Removed transitions are missing.
+func (red *Reduction) TranslateLiveness(levels map[string]Liveness) map[string]Liveness {
+	orig := map[string]Liveness{}
+	for id, l := range levels {
+		for _, t := range red.Transitions[id] {
+			orig[t] = l
+		}
+		for _, alt := range red.Alternatives[id] {
+			for _, t := range alt {
+				orig[t] = l
+			}
+		}
+	}
+	return orig
+}
@@ -0,0 +1,142 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// P1 ──►[T1]──► P2 ──►[T2]──► P3 ──►[T3]──► P1
+func buildRingNet() *Net {
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	t3 := net.NewTransition("T3")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p3, 1)
+	p3.ConnectTo(t3, 1)
+	t3.ConnectTo(p1, 1)
+	p1.AddTokens(1)
+	return net
+}
+
+func TestReduceSeriesTransitions(test *testing.T) {
+	net := buildRingNet()
+	red, err := net.Reduce(WithRules(FuseSeriesTransitions))
+	assert.NoError(test, err)
+	// (P1) ◄──► [T1_T2_T3]
+	assert.Equal(test, 1, len(red.Net.places))
+	assert.Equal(test, 1, len(red.Net.transitions))
+	assert.Equal(test, []string{"T1", "T2", "T3"}, red.Transitions["T1_T2_T3"])
+	assert.ElementsMatch(test, []string{"P2", "P3"}, red.RemovedPlaces)
+	assert.Equal(test, []string{"T1", "T2", "T3", "T1", "T2", "T3"}, red.TranslateSequence([]string{"T1_T2_T3", "T1_T2_T3"}))
+
+	levels, err := red.Net.Liveness()
+	assert.NoError(test, err)
+	assert.Equal(test, map[string]Liveness{"T1": Live, "T2": Live, "T3": Live}, red.TranslateLiveness(levels))
+}
+
+func TestReduceSeriesPlaces(test *testing.T) {
+	net := buildRingNet()
+	red, err := net.Reduce(WithRules(FuseSeriesPlaces), Keeping("T3"))
+	assert.NoError(test, err)
+	// (P1_P2_P3) ◄──► [T3]
+	assert.Equal(test, 1, len(red.Net.places))
+	assert.Equal(test, []string{"P1", "P2", "P3"}, red.Places["P1_P2_P3"])
+	assert.Equal(test, []string{"T1", "T2"}, red.RemovedTransitions)
+	bounds, err := red.Net.Bounds()
+	assert.NoError(test, err)
+	assert.Equal(test, Marking{"P1": 1, "P2": 1, "P3": 1}, red.TranslateBounds(bounds))
+
+	// with every rule the fused place is a self-loop too: [T3] is always enabled
+	red, err = net.Reduce(Keeping("T3"))
+	assert.NoError(test, err)
+	assert.Equal(test, 0, len(red.Net.places))
+	assert.Equal(test, 1, len(red.Net.transitions))
+	assert.Equal(test, map[string]int{"P1": 1, "P2": 1, "P3": 1}, red.SelfLoopPlaces)
+	levels, err := red.Net.Liveness()
+	assert.NoError(test, err)
+	assert.Equal(test, map[string]Liveness{"T3": Live}, red.TranslateLiveness(levels))
+}
+
+func TestReduceParallel(test *testing.T) {
+	/* build net:
+
+	       ┌─►[T1]─┐
+	(P1) ──┤       ├──► (P2) ──►[T3]──► (P1)
+	       └─►[T2]─┘       ──► (P3) ──►[T3]
+
+	   (S) ◄──► [T3]   (self-loop place)
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	s := net.NewPlace("S")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	t3 := net.NewTransition("T3")
+	p1.ConnectTo(t1, 1)
+	p1.ConnectTo(t2, 1)
+	t1.ConnectTo(p2, 1)
+	t1.ConnectTo(p3, 1)
+	t2.ConnectTo(p2, 1)
+	t2.ConnectTo(p3, 1)
+	p2.ConnectTo(t3, 1)
+	p3.ConnectTo(t3, 1)
+	s.ConnectTo(t3, 1)
+	t3.ConnectTo(s, 1)
+	t3.ConnectTo(p1, 1)
+	p1.AddTokens(1)
+	s.AddTokens(1)
+
+	red, err := net.Reduce(WithRules(FuseParallelPlaces, FuseParallelTransitions, EliminateSelfLoopPlaces))
+	assert.NoError(test, err)
+	assert.Equal(test, 2, len(red.Net.places))
+	assert.Equal(test, 2, len(red.Net.transitions))
+	assert.ElementsMatch(test, []string{"P2", "P3"}, red.Places["P3"])
+	assert.Equal(test, [][]string{{"T1"}}, red.Alternatives["T2"])
+	assert.Equal(test, map[string]int{"S": 1}, red.SelfLoopPlaces)
+
+	levels, err := red.Net.Liveness()
+	assert.NoError(test, err)
+	assert.Equal(test, map[string]Liveness{"T1": Live, "T2": Live, "T3": Live}, red.TranslateLiveness(levels))
+}
+
+func TestReduceEnableArc(test *testing.T) {
+	net := buildRingNet()
+	p4 := net.NewPlace("P4")
+	net.transitions[0].(*Transition).InhibitedBy(p4)
+
+	red, err := net.Reduce(WithRules(FuseSeriesTransitions))
+	assert.NoError(test, err)
+	// T1 is never fused: only P3 between T2 and T3 is removed
+	assert.Equal(test, []string{"P3"}, red.RemovedPlaces)
+	assert.Equal(test, []string{"T2", "T3"}, red.Transitions["T2_T3"])
+	assert.Equal(test, 3, len(red.Net.places))
+	assert.Equal(test, 2, len(red.Net.transitions))
+}
+
+func TestReduceIdCollision(test *testing.T) {
+	net := buildRingNet()
+	net.NewPlace("P1_P2")
+	net.NewTransition("T1_T2")
+
+	// fused ids never replace existing ones
+	red, err := net.Reduce(WithRules(FuseSeriesPlaces), Keeping("T2", "T3", "P1_P2"))
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"P1", "P2"}, red.Places["P1_P2_2"])
+	assert.Equal(test, []string{"P1_P2"}, red.Places["P1_P2"])
+	assert.Equal(test, 3, len(red.Net.places))
+
+	red, err = net.Reduce(WithRules(FuseSeriesTransitions), Keeping("T3", "T1_T2"))
+	assert.NoError(test, err)
+	assert.Equal(test, []string{"T1", "T2"}, red.Transitions["T1_T2_2"])
+	assert.Equal(test, []string{"T1_T2"}, red.Transitions["T1_T2"])
+	assert.Equal(test, 3, len(red.Net.transitions))
+}
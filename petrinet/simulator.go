@@ -0,0 +1,96 @@
+package petrinet
+
+import (
+	"math/rand"
+)
+
+/*
+Simulator runs a net with the synchronous step engine: like 'Step()', it picks
+among enabled transitions with the net policy (see 'SetChooser()'). Without a
+policy it picks uniformly at random using 'rnd', so running again from the same
+marking with a '*rand.Rand' seeded the same way gives the same trace.
+*/
+type Simulator struct {
+	net     *Net
+	rnd     *rand.Rand
+	initial state
+}
+
+// Single firing of a simulation
+type TraceStep struct {
+	Transition string  // id of fired transition
+	Marking    Marking // marking reached
+}
+
+type Trace struct {
+	Initial Marking
+	Steps   []TraceStep
+	Dead    bool // stopped because no transition is enabled
+	Reached bool // stopped because predicate holds (see 'RunUntil()')
+}
+
+// New simulator starting from current marking of the net
+func NewSimulator(n *Net, rnd *rand.Rand) *Simulator {
+	return &Simulator{net: n, rnd: rnd, initial: n.currentState()}
+}
+
+// Restore the initial marking (random choices continue the sequence of 'rnd')
+func (sim *Simulator) Reset() {
+	sim.net.restore(sim.initial)
+}
+
+// Fire (at most) 'steps' transitions
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (sim *Simulator) Run(steps int) *Trace {
+	return sim.RunUntil(steps, func(Marking) bool { return false })
+}
+
+// Fire transitions until 'stop' holds in current marking (checked before
+// every firing), no transition is enabled or 'steps' transitions are fired.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (sim *Simulator) RunUntil(steps int, stop func(Marking) bool) *Trace {
+	n := sim.net
+	trace := &Trace{Initial: n.Marking(), Steps: []TraceStep{}}
+	m := trace.Initial
+	for k := 0; ; k++ {
+		if stop(m) {
+			trace.Reached = true
+			break
+		}
+		if k == steps {
+			break
+		}
+		enabled := n.EnabledTransitions()
+		if len(enabled) == 0 {
+			trace.Dead = true
+			break
+		}
+		t := sim.choose(enabled)
+		if err := n.Fire(t); err != nil {
+			logger.Panicf("RunUntil() failed: %v", err)
+		}
+		m = n.Marking()
+		trace.Steps = append(trace.Steps, TraceStep{t.Id(), m})
+	}
+	return trace
+}
+
+// Transition picked by net policy, or at random without a policy
+func (sim *Simulator) choose(enabled []TransitionI) TransitionI {
+	n := sim.net
+	n.chooserMu.Lock()
+	defer n.chooserMu.Unlock()
+	if n.chooser == nil {
+		return enabled[sim.rnd.Intn(len(enabled))]
+	}
+	return n.chooser.Choose(n, enabled)
+}
+
+// Ids of fired transitions
+func (tr *Trace) Transitions() []string {
+	ids := make([]string, len(tr.Steps))
+	for k, step := range tr.Steps {
+		ids[k] = step.Transition
+	}
+	return ids
+}
@@ -0,0 +1,67 @@
+package petrinet
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulator(test *testing.T) {
+	/* build net:
+
+	         ┌──►[Up]───┐
+	(Low) ◄──┤          ├──► (High)
+	         └──[Down]◄─┘
+	(Low) ──►[Stop]
+
+	*/
+	net := NewNet("TestNet")
+	low := net.NewPlace("Low")
+	high := net.NewPlace("High")
+	up := net.NewTransition("Up")
+	down := net.NewTransition("Down")
+	stop := net.NewTransition("Stop")
+	low.ConnectTo(up, 1)
+	up.ConnectTo(high, 1)
+	high.ConnectTo(down, 1)
+	down.ConnectTo(low, 1)
+	low.ConnectTo(stop, 1)
+	low.AddTokens(3)
+
+	sim := NewSimulator(net, rand.New(rand.NewSource(7)))
+	trace := sim.Run(20)
+	assert.Equal(test, Marking{"Low": 3, "High": 0}, trace.Initial)
+	assert.LessOrEqual(test, len(trace.Steps), 20)
+	stops := 0
+	for _, step := range trace.Steps {
+		if step.Transition == "Stop" {
+			stops++
+		}
+		assert.Equal(test, 3, step.Marking["Low"]+step.Marking["High"]+stops)
+	}
+
+	// same seed from the same marking: same trace
+	sim.Reset()
+	assert.Equal(test, trace, NewSimulator(net, rand.New(rand.NewSource(7))).Run(20))
+
+	// run to the end: every token is eventually stopped
+	sim.Reset()
+	trace = sim.Run(1000)
+	assert.True(test, trace.Dead)
+	assert.Equal(test, Marking{"Low": 0, "High": 0}, net.Marking())
+
+	sim.Reset()
+	sim = NewSimulator(net, rand.New(rand.NewSource(7)))
+	trace = sim.RunUntil(1000, func(m Marking) bool { return m["High"] == 2 })
+	assert.True(test, trace.Reached)
+	assert.Equal(test, 2, high.Tokens())
+	assert.Equal(test, "Up", trace.Steps[len(trace.Steps)-1].Transition)
+
+	// net policy is followed: Stop wins over Up
+	sim.Reset()
+	stop.SetPriority(1)
+	net.SetChooser(NewPriorityChooser(nil))
+	trace = sim.Run(1000)
+	assert.Equal(test, []string{"Stop", "Stop", "Stop"}, trace.Transitions())
+}
@@ -0,0 +1,194 @@
+package petrinet
+
+import "sort"
+
+// Siphon: set of places S such that •S ⊆ S•. Once empty, a siphon stays empty.
+// Trap: set of places S such that S• ⊆ •S. Once marked, a trap stays marked.
+// Both are computed on weighted Arcs only (EnableArc is ignored).
+
+// Minimal siphons of the net (place ids)
+func (n *Net) Siphons() [][]string {
+	st := n.structure()
+	return n.placeIds(minimalPlaceSets(len(n.places), st.placePre, st.transPre))
+}
+
+// Minimal traps of the net (place ids)
+func (n *Net) Traps() [][]string {
+	st := n.structure()
+	return n.placeIds(minimalPlaceSets(len(n.places), st.placePost, st.transPost))
+}
+
+/*
+Result of Commoner property check: every siphon contains a trap marked in
+the current marking.
+For free-choice nets (Commoner's theorem) the net is live if and only if the
+property holds. Nets with EnableArcs, guards or capacities are never considered free-choice.
+*/
+type CommonerReport struct {
+	FreeChoice bool       // the theorem applies to the net
+	Holds      bool       // the property holds
+	Failing    [][]string // minimal siphons without an initially marked trap
+}
+
+// Check Commoner property on the current marking
+func (n *Net) Commoner() *CommonerReport {
+	st := n.structure()
+	s := n.currentState()
+	report := &CommonerReport{
+		FreeChoice: st.isFreeChoice() && len(st.enableArcs) == 0 && !n.hasGuards() && !n.hasCapacities(),
+		Failing:    [][]string{},
+	}
+	for _, siphon := range minimalPlaceSets(len(n.places), st.placePre, st.transPre) {
+		marked := false
+		for _, p := range st.maxTrap(siphon) {
+			if s[p] > 0 {
+				marked = true
+				break
+			}
+		}
+		if !marked {
+			report.Failing = append(report.Failing, n.placeIds([][]int{siphon})[0])
+		}
+	}
+	report.Holds = len(report.Failing) == 0
+	return report
+}
+
+// Largest trap contained in 'places': remove places with an output
+// transition not producing back into the set, until nothing changes.
+func (st *structure) maxTrap(places []int) []int {
+	in := map[int]bool{}
+	for _, p := range places {
+		in[p] = true
+	}
+	for changed := true; changed; {
+		changed = false
+		for p := range in {
+			for _, t := range st.placePost[p] {
+				if !intersects(st.transPost[t], in) {
+					delete(in, p)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	trap := []int{}
+	for p := range in {
+		trap = append(trap, p)
+	}
+	sort.Ints(trap)
+	return trap
+}
+
+/*
+Minimal non-empty sets of places S such that every transition in 'adjacent(S)'
+is connected to S by 'cover':
+  - siphons: adjacent = •p (placePre), cover = •t (transPre)
+  - traps: adjacent = p• (placePost), cover = t• (transPost)
+*/
+func minimalPlaceSets(nplaces int, adjacent, cover [][]int) [][]int {
+	found := [][]int{}
+	visited := map[string]bool{}
+	var search func(set map[int]bool)
+	search = func(set map[int]bool) {
+		places := sortedKeys(set)
+		key := state(places).key()
+		if visited[key] {
+			return
+		}
+		visited[key] = true
+		// find a transition violating the constraint
+		violated := -1
+		for _, p := range places {
+			for _, t := range adjacent[p] {
+				if !intersects(cover[t], set) {
+					violated = t
+					break
+				}
+			}
+			if violated >= 0 {
+				break
+			}
+		}
+		if violated < 0 {
+			found = append(found, places)
+			return
+		}
+		// one of its places must be added to the set
+		for _, p := range cover[violated] {
+			set[p] = true
+			search(set)
+			delete(set, p)
+		}
+	}
+	for p := 0; p < nplaces; p++ {
+		search(map[int]bool{p: true})
+	}
+	return minimalSets(found)
+}
+
+func intersects(s []int, set map[int]bool) bool {
+	for _, x := range s {
+		if set[x] {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(set map[int]bool) []int {
+	keys := make([]int, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// Remove duplicated sets and sets strictly containing another one
+func minimalSets(sets [][]int) [][]int {
+	minimal := [][]int{}
+	for a, s1 := range sets {
+		keep := true
+		for b, s2 := range sets {
+			if a == b || !isSubset(s2, s1) {
+				continue
+			}
+			// on equal sets keep the first one only
+			if len(s2) < len(s1) || b < a {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			minimal = append(minimal, s1)
+		}
+	}
+	return minimal
+}
+
+// Test if sorted set 's1' is contained in sorted set 's2'
+func isSubset(s1, s2 []int) bool {
+	j := 0
+	for _, x := range s1 {
+		for j < len(s2) && s2[j] < x {
+			j++
+		}
+		if j == len(s2) || s2[j] != x {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *Net) placeIds(sets [][]int) [][]string {
+	ids := make([][]string, len(sets))
+	for k, set := range sets {
+		ids[k] = make([]string, len(set))
+		for i, p := range set {
+			ids[k][i] = n.places[p].Id()
+		}
+	}
+	return ids
+}
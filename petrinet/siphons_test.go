@@ -0,0 +1,53 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSiphonsAndTraps(test *testing.T) {
+	/* build net:
+
+	(P1)──►[T1]──►(P2)──►[T2]──►(P1)
+	         ▲
+	(P3)─────┘
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p1.ConnectTo(t1, 1)
+	p3.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p1, 1)
+	p1.AddTokens(1)
+
+	assert.ElementsMatch(test, [][]string{{"P1", "P2"}, {"P3"}}, net.Siphons())
+	assert.ElementsMatch(test, [][]string{{"P1", "P2"}}, net.Traps())
+
+	// siphon P3 is empty and stays empty: T1 can never fire
+	report := net.Commoner()
+	assert.True(test, report.FreeChoice)
+	assert.False(test, report.Holds)
+	assert.Equal(test, [][]string{{"P3"}}, report.Failing)
+
+	// without P3 the net is live
+	net = NewNet("TestNet")
+	p1 = net.NewPlace("P1")
+	p2 = net.NewPlace("P2")
+	t1 = net.NewTransition("T1")
+	t2 = net.NewTransition("T2")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p1, 1)
+	p1.AddTokens(1)
+	report = net.Commoner()
+	assert.True(test, report.FreeChoice)
+	assert.True(test, report.Holds)
+}
@@ -0,0 +1,182 @@
+package petrinet
+
+import (
+	"fmt"
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/golang-collections/collections/set"
+)
+
+// Policy picking the transition to fire among the enabled ones
+type Chooser interface {
+	// 'enabled' is never empty and follows net order (see 'Net.NewTransition()')
+	Choose(n *Net, enabled []TransitionI) TransitionI
+}
+
+// Always choose the first enabled transition
+type firstChooser struct{}
+
+func NewFirstChooser() Chooser {
+	return &firstChooser{}
+}
+func (c *firstChooser) Choose(n *Net, enabled []TransitionI) TransitionI {
+	return enabled[0]
+}
+
+// Choose the first enabled transition following the last one chosen (in net order)
+type roundRobinChooser struct {
+	next int // position in net of the next transition to consider
+}
+
+func NewRoundRobinChooser() Chooser {
+	return &roundRobinChooser{}
+}
+func (c *roundRobinChooser) Choose(n *Net, enabled []TransitionI) TransitionI {
+	for k := 0; k < len(n.transitions); k++ {
+		j := (c.next + k) % len(n.transitions)
+		for _, t := range enabled {
+			if t == n.transitions[j] {
+				c.next = j + 1
+				return t
+			}
+		}
+	}
+	return enabled[0]
+}
+
+// Choose uniformly at random, with a reproducible sequence for a given seed
+type randomChooser struct {
+	rnd *rand.Rand
+}
+
+func NewRandomChooser(seed int64) Chooser {
+	return &randomChooser{rnd: rand.New(rand.NewSource(seed))}
+}
+func (c *randomChooser) Choose(n *Net, enabled []TransitionI) TransitionI {
+	return enabled[c.rnd.Intn(len(enabled))]
+}
+
+// Set policy choosing the transition to fire, used by 'Step()' and by
+// running net when enabled transitions compete for the same tokens.
+// Without a policy 'Step()' fires the first enabled transition and running
+// transitions race for tokens.
+func (n *Net) SetChooser(c Chooser) {
+	n.chooserMu.Lock()
+	defer n.chooserMu.Unlock()
+	n.chooser = c
+}
+func (n *Net) choose(enabled []TransitionI) TransitionI {
+	n.chooserMu.Lock()
+	defer n.chooserMu.Unlock()
+	if n.chooser == nil {
+		return enabled[0]
+	}
+	return n.chooser.Choose(n, enabled)
+}
+
+// Transitions enabled in current marking (in net order)
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) EnabledTransitions() []TransitionI {
+	return n.enabledAmong(n.transitions)
+}
+
+// Enabled transitions among 'candidates' (their places must be locked),
+// keeping track of the time they have been enabled since (see 'refreshEnabling()').
+func (n *Net) enabledAmong(candidates []TransitionI) []TransitionI {
+	enabled := []TransitionI{}
+	for _, ti := range candidates {
+		if t := ti.(*Transition); t.updateEnabling() {
+			enabled = append(enabled, t)
+		}
+	}
+	return enabled
+}
+
+// Test if transition is enabled (its places must be locked), stamping the
+// net tick when it's found enabled the first time and clearing it when disabled
+func (t *Transition) updateEnabling() bool {
+	if !t.isEnabled() {
+		atomic.StoreUint64(&t.enabledSince, 0)
+		t.enabledAt = undef
+		return false
+	}
+	if atomic.LoadUint64(&t.enabledSince) == 0 {
+		atomic.CompareAndSwapUint64(&t.enabledSince, 0, atomic.AddUint64(&t.net.tick, 1))
+	}
+	if t.enabledAt == undef {
+		t.enabledAt = t.net.clock.Now()
+	}
+	return true
+}
+
+// Update enabling time of transitions connected to 'changed' places, right
+// after their tokens changed (see 'updateEnabling()').
+func refreshEnabling(changed []PlaceI, held *set.Set) {
+	forConnected(changed, held, func(t *Transition) {
+		t.updateEnabling()
+	})
+}
+
+// Invoke 'f' on transitions connected to 'changed' places, with their places
+// locked. Places in 'held' are already locked by the caller, the other places
+// are only tried: transitions with a busy place are left to the next
+// 'enabledAmong()' on them.
+func forConnected(changed []PlaceI, held *set.Set, f func(*Transition)) {
+	seen := map[*Transition]bool{}
+	for _, p := range changed {
+		arcs := append(append([]ArcI{}, p.(*Place).arcs_out...), p.(*Place).arcs_in...)
+		for _, arc := range arcs {
+			t := arc.Transition().(*Transition)
+			if seen[t] {
+				continue
+			}
+			seen[t] = true
+			locked := []PlaceI{}
+			busy := false
+			uniquePlaces(t).Do(func(i interface{}) {
+				if q := i.(PlaceI); !busy && !held.Has(q) {
+					if q.trylock() {
+						locked = append(locked, q)
+					} else {
+						busy = true
+					}
+				}
+			})
+			if !busy {
+				f(t)
+			}
+			for _, q := range locked {
+				q.unlock()
+			}
+		}
+	}
+}
+
+// Synchronously fire an enabled transition of the net
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) Fire(t TransitionI) error {
+	tr, ok := t.(*Transition)
+	if !ok || tr.net != n {
+		return fmt.Errorf("Fire() failed for [%s]: transition [%s] doesn't belong to net", n.id, t.Id())
+	}
+	if !fireTransition(tr, false) {
+		return fmt.Errorf("Fire() failed for [%s]: transition [%s] is not enabled", n.id, t.Id())
+	}
+	return NoError
+}
+
+// Synchronously fire one enabled transition, picked by net policy (see 'SetChooser()').
+// Returns the transition fired, or nil if no transition is enabled.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) Step() TransitionI {
+	enabled := n.EnabledTransitions()
+	if len(enabled) == 0 {
+		return nil
+	}
+	t := n.choose(enabled)
+	if err := n.Fire(t); err != nil {
+		logger.Panicf("Step() failed: %v", err)
+	}
+	return t
+}
@@ -0,0 +1,76 @@
+package petrinet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Fire 'steps' times (or until dead) and return ids of fired transitions
+func runSteps(net *Net, steps int) []string {
+	trace := []string{}
+	for k := 0; k < steps; k++ {
+		t := net.Step()
+		if t == nil {
+			break
+		}
+		trace = append(trace, t.Id())
+	}
+	return trace
+}
+
+func TestFire(test *testing.T) {
+	/* build net:
+
+	(P1)──►[T1]──►(P2)──►[T2]
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	p2.ConnectTo(t2, 2)
+
+	p1.AddTokens(2)
+	assert.Equal(test, []TransitionI{t1}, net.EnabledTransitions())
+	assert.Error(test, net.Fire(t2))
+	assert.NoError(test, net.Fire(t1))
+	assert.Equal(test, 1, p2.Tokens())
+	assert.NoError(test, net.Fire(t1))
+	assert.Equal(test, []TransitionI{t2}, net.EnabledTransitions())
+	assert.Equal(test, t2, net.Step())
+	assert.Nil(test, net.Step()) // dead
+	assert.Equal(test, Marking{"P1": 0, "P2": 0}, net.Marking())
+
+	other := NewNet("OtherNet").NewTransition("T1")
+	assert.Error(test, net.Fire(other))
+}
+
+func TestChoosers(test *testing.T) {
+	/* build net:
+
+	(P) ◄──► [T1]
+	(P) ◄──► [T2]
+	(P) ◄──► [T3]
+
+	*/
+	build := func(c Chooser) *Net {
+		net := buildSelfLoops("T1", "T2", "T3")
+		net.SetChooser(c)
+		return net
+	}
+	assert.Equal(test, []string{"T1", "T1", "T1", "T1"}, runSteps(build(NewFirstChooser()), 4))
+	assert.Equal(test, []string{"T1", "T2", "T3", "T1"}, runSteps(build(NewRoundRobinChooser()), 4))
+
+	// same seed, same run
+	trace := runSteps(build(NewRandomChooser(42)), 50)
+	assert.Equal(test, trace, runSteps(build(NewRandomChooser(42)), 50))
+	fired := map[string]bool{}
+	for _, id := range trace {
+		fired[id] = true
+	}
+	assert.Equal(test, 3, len(fired))
+}
@@ -0,0 +1,211 @@
+package petrinet
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+/*
+Generalized stochastic Petri nets (GSPN): transitions with a rate (see
+'SetRate()') fire after an exponentially distributed delay, transitions
+with rate 'Immediate' fire first, in zero time. Among enabled
+immediate transitions the highest priority ones compete, chosen at random
+with probability proportional to their weight (see 'SetWeight()').
+Simulation follows Gillespie algorithm on virtual time (in the same unit of
+rates) and never touches real Place tokens. Every transition that gets
+enabled must have a rate, otherwise simulation fails.
+*/
+type StochasticSimulator struct {
+	net     *Net
+	seed    int64
+	rnd     *rand.Rand
+	initial state
+	index   map[*Transition]int // position of transitions in net
+}
+
+// Rate of transitions firing in zero time (see 'SetRate()')
+const Immediate = 0.0
+
+type StochasticEstimate struct {
+	Time       float64            // observed time
+	MeanTokens map[string]float64 // mean tokens per place
+	Throughput map[string]float64 // mean firings per time unit per transition
+}
+
+// New stochastic simulator starting from current marking of the net.
+// Every estimate restarts from that marking with the random sequence of 'seed'.
+func NewStochasticSimulator(n *Net, seed int64) *StochasticSimulator {
+	sim := &StochasticSimulator{net: n, seed: seed, rnd: rand.New(rand.NewSource(seed)), initial: n.currentState()}
+	sim.index = map[*Transition]int{}
+	for j, t := range n.transitions {
+		sim.index[t.(*Transition)] = j
+	}
+	return sim
+}
+
+// Set exponential firing rate ('Immediate' for transitions firing in zero time).
+// Rate is unset by default.
+func (t *Transition) SetRate(rate float64) {
+	if rate < 0 || math.IsNaN(rate) {
+		logger.Panicf("Transition [%s] cannot have rate %v", t.id, rate)
+	}
+	t.rate = rate
+}
+
+// Error if rate of transition is unset
+func (t *Transition) checkRate() error {
+	if t.rate == undef {
+		return fmt.Errorf("transition [%s] has no rate (see 'SetRate()')", t.id)
+	}
+	return NoError
+}
+
+// Estimate long run behaviour with a single simulation up to 'horizon',
+// discarding observations before 'warmup'.
+func (sim *StochasticSimulator) SteadyState(warmup, horizon float64) (*StochasticEstimate, error) {
+	if warmup < 0 || horizon <= warmup {
+		return nil, fmt.Errorf("SteadyState() failed for [%s]: invalid interval [%v, %v]", sim.net.id, warmup, horizon)
+	}
+	n := sim.net
+	tokens := make([]float64, len(n.places))
+	firings := make([]int, len(n.transitions))
+	sim.rnd.Seed(sim.seed)
+	err := sim.run(horizon,
+		func(s state, from, to float64) {
+			if from < warmup {
+				from = warmup
+			}
+			if to > from {
+				for i, toks := range s {
+					tokens[i] += float64(toks) * (to - from)
+				}
+			}
+		},
+		func(j int, at float64) {
+			if at >= warmup {
+				firings[j]++
+			}
+		})
+	if err != nil {
+		return nil, fmt.Errorf("SteadyState() failed for [%s]: %v", n.id, err)
+	}
+	e := sim.newEstimate(horizon - warmup)
+	for i, p := range n.places {
+		e.MeanTokens[p.Id()] = tokens[i] / e.Time
+	}
+	for j, t := range n.transitions {
+		e.Throughput[t.Id()] = float64(firings[j]) / e.Time
+	}
+	return e, NoError
+}
+
+// Estimate behaviour at given (increasing) times averaging 'runs' simulations:
+// mean tokens in every place at each time, and mean firings per time unit up to it.
+func (sim *StochasticSimulator) Transient(times []float64, runs int) ([]*StochasticEstimate, error) {
+	n := sim.net
+	if len(times) == 0 || runs <= 0 {
+		return nil, fmt.Errorf("Transient() failed for [%s]: no times or runs", n.id)
+	}
+	horizon := times[len(times)-1]
+	tokens := make([][]float64, len(times))
+	firings := make([][]int, len(times))
+	for k := range times {
+		tokens[k] = make([]float64, len(n.places))
+		firings[k] = make([]int, len(n.transitions))
+	}
+	sim.rnd.Seed(sim.seed)
+	for r := 0; r < runs; r++ {
+		err := sim.run(horizon,
+			func(s state, from, to float64) {
+				for k, at := range times {
+					if from <= at && (at < to || to == horizon) {
+						for i, toks := range s {
+							tokens[k][i] += float64(toks)
+						}
+					}
+				}
+			},
+			func(j int, at float64) {
+				for k := range times {
+					if at <= times[k] {
+						firings[k][j]++
+					}
+				}
+			})
+		if err != nil {
+			return nil, fmt.Errorf("Transient() failed for [%s]: %v", n.id, err)
+		}
+	}
+	estimates := make([]*StochasticEstimate, len(times))
+	for k, at := range times {
+		e := sim.newEstimate(at)
+		for i, p := range n.places {
+			e.MeanTokens[p.Id()] = tokens[k][i] / float64(runs)
+		}
+		for j, t := range n.transitions {
+			if at > 0 {
+				e.Throughput[t.Id()] = float64(firings[k][j]) / float64(runs) / at
+			}
+		}
+		estimates[k] = e
+	}
+	return estimates, NoError
+}
+
+func (sim *StochasticSimulator) newEstimate(observed float64) *StochasticEstimate {
+	return &StochasticEstimate{Time: observed, MeanTokens: map[string]float64{}, Throughput: map[string]float64{}}
+}
+
+// Simulate from initial marking up to 'horizon': 'observe' is invoked for
+// every interval of time spent in a state, 'fired' for every firing.
+// Errors are left to the caller to report.
+func (sim *StochasticSimulator) run(horizon float64, observe func(s state, from, to float64), fired func(j int, at float64)) error {
+	n := sim.net
+	s := sim.initial.clone()
+	now := 0.0
+	vanishing := 0 // consecutive immediate firings
+	for {
+		immediate, timed := []TransitionI{}, []TransitionI{}
+		for _, ti := range n.transitions {
+			if t := ti.(*Transition); n.isEnabledAt(t, s) {
+				if err := t.checkRate(); err != nil {
+					return err
+				}
+				if t.rate == Immediate {
+					immediate = append(immediate, t)
+				} else {
+					timed = append(timed, t)
+				}
+			}
+		}
+		if len(immediate) > 0 {
+			if vanishing++; vanishing > AnalysisLimit {
+				return fmt.Errorf("immediate transitions fire forever")
+			}
+			t := pickWeighted(sim.rnd, highestPriority(immediate), func(t *Transition) float64 { return t.weight })
+			s = n.fireAt(t, s)
+			fired(sim.index[t], now)
+			continue
+		}
+		vanishing = 0
+		total := 0.0
+		for _, t := range timed {
+			total += t.(*Transition).rate
+		}
+		if total == 0 {
+			observe(s, now, horizon) // dead marking
+			return NoError
+		}
+		dt := sim.rnd.ExpFloat64() / total
+		if now+dt >= horizon {
+			observe(s, now, horizon)
+			return NoError
+		}
+		observe(s, now, now+dt)
+		now += dt
+		t := pickWeighted(sim.rnd, timed, func(t *Transition) float64 { return t.rate })
+		s = n.fireAt(t, s)
+		fired(sim.index[t], now)
+	}
+}
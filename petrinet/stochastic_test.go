@@ -0,0 +1,135 @@
+package petrinet
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// (Off)──►[TOn]──►(On)──►[TOff]──►(Off)
+func buildOnOffNet(on, off float64) *Net {
+	net := NewNet("TestNet")
+	pOff := net.NewPlace("Off")
+	pOn := net.NewPlace("On")
+	tOn := net.NewTransition("TOn")
+	tOff := net.NewTransition("TOff")
+	pOff.ConnectTo(tOn, 1)
+	tOn.ConnectTo(pOn, 1)
+	pOn.ConnectTo(tOff, 1)
+	tOff.ConnectTo(pOff, 1)
+	tOn.SetRate(on)
+	tOff.SetRate(off)
+	pOff.AddTokens(1)
+	return net
+}
+
+func TestStochasticSteadyState(test *testing.T) {
+	net := buildOnOffNet(1, 3)
+	sim := NewStochasticSimulator(net, 1)
+	e, err := sim.SteadyState(100, 20000)
+	assert.NoError(test, err)
+	// two states Markov chain: P(On) = 1/(1+3)
+	assert.InDelta(test, 0.25, e.MeanTokens["On"], 0.02)
+	assert.InDelta(test, 0.75, e.MeanTokens["Off"], 0.02)
+	assert.InDelta(test, 0.75, e.Throughput["TOn"], 0.03)
+	assert.InDelta(test, e.Throughput["TOn"], e.Throughput["TOff"], 0.001)
+	// real tokens are untouched
+	assert.Equal(test, Marking{"Off": 1, "On": 0}, net.Marking())
+
+	// same seed, same estimate
+	again, err := NewStochasticSimulator(net, 1).SteadyState(100, 20000)
+	assert.NoError(test, err)
+	assert.Equal(test, e, again)
+
+	_, err = sim.SteadyState(10, 5)
+	assert.Error(test, err)
+
+	// every transition needs a rate
+	net.NewTransition("TNoRate")
+	_, err = NewStochasticSimulator(net, 1).SteadyState(0, 10)
+	assert.EqualError(test, err, "SteadyState() failed for [TestNet]: transition [TNoRate] has no rate (see 'SetRate()')")
+	_, err = NewStochasticSimulator(net, 1).Transient([]float64{10}, 1)
+	assert.EqualError(test, err, "Transient() failed for [TestNet]: transition [TNoRate] has no rate (see 'SetRate()')")
+}
+
+func TestStochasticImmediate(test *testing.T) {
+	/* build net:
+
+	(Idle)──►[T]──►(Choice)──►[A] (weight 3)──►(Idle)
+	         rate 2     │
+	                    └────►[B] (weight 1)──►(Idle)
+
+	*/
+	net := NewNet("TestNet")
+	idle := net.NewPlace("Idle")
+	choice := net.NewPlace("Choice")
+	t := net.NewTransition("T")
+	a := net.NewTransition("A")
+	b := net.NewTransition("B")
+	idle.ConnectTo(t, 1)
+	t.ConnectTo(choice, 1)
+	choice.ConnectTo(a, 1)
+	a.ConnectTo(idle, 1)
+	choice.ConnectTo(b, 1)
+	b.ConnectTo(idle, 1)
+	t.SetRate(2)
+	a.SetRate(Immediate)
+	b.SetRate(Immediate)
+	a.SetWeight(3)
+	idle.AddTokens(1)
+
+	e, err := NewStochasticSimulator(net, 3).SteadyState(0, 10000)
+	assert.NoError(test, err)
+	assert.Equal(test, 0.0, e.MeanTokens["Choice"]) // vanishing marking
+	assert.InDelta(test, 2, e.Throughput["T"], 0.1)
+	assert.InDelta(test, 1.5, e.Throughput["A"], 0.1)
+	assert.InDelta(test, 0.5, e.Throughput["B"], 0.05)
+
+	// higher priority wins regardless of weights
+	b.SetPriority(1)
+	e, err = NewStochasticSimulator(net, 3).SteadyState(0, 1000)
+	assert.NoError(test, err)
+	assert.Equal(test, 0.0, e.Throughput["A"])
+
+	// immediate transitions firing forever
+	loop := NewNet("TestNet")
+	p := loop.NewPlace("P")
+	tl := loop.NewTransition("T")
+	p.ConnectTo(tl, 1)
+	tl.ConnectTo(p, 1)
+	p.AddTokens(1)
+	_, err = NewStochasticSimulator(loop, 1).SteadyState(0, 1)
+	assert.Error(test, err) // unset rate
+	tl.SetRate(Immediate)
+	_, err = NewStochasticSimulator(loop, 1).SteadyState(0, 1)
+	assert.Error(test, err)
+}
+
+func TestStochasticTransient(test *testing.T) {
+	/* build net:
+
+	(P)──►[T]──►(Q)
+	     rate 1
+
+	*/
+	net := NewNet("TestNet")
+	p := net.NewPlace("P")
+	q := net.NewPlace("Q")
+	t := net.NewTransition("T")
+	p.ConnectTo(t, 1)
+	t.ConnectTo(q, 1)
+	t.SetRate(1)
+	p.AddTokens(1)
+
+	times := []float64{0.5, 1, 2}
+	estimates, err := NewStochasticSimulator(net, 5).Transient(times, 5000)
+	assert.NoError(test, err)
+	for k, at := range times {
+		// exponential decay
+		assert.Equal(test, at, estimates[k].Time)
+		assert.InDelta(test, math.Exp(-at), estimates[k].MeanTokens["P"], 0.03)
+		assert.InDelta(test, 1-math.Exp(-at), estimates[k].MeanTokens["Q"], 0.03)
+		assert.InDelta(test, (1-math.Exp(-at))/at, estimates[k].Throughput["T"], 0.03)
+	}
+}
@@ -0,0 +1,91 @@
+package petrinet
+
+/*
+Structure of the net (pre/post sets) used by structural analysis.
+Places and transitions are referenced by their position in Net.places and
+Net.transitions. Only weighted Arcs are considered: EnableArc never moves
+tokens, so it's kept apart in 'enableArcs'.
+*/
+type structure struct {
+	placePre   [][]int // place -> transitions producing into it (•p)
+	placePost  [][]int // place -> transitions consuming from it (p•)
+	transPre   [][]int // transition -> places it consumes from (•t)
+	transPost  [][]int // transition -> places it produces into (t•)
+	ordinary   bool    // every Arc has weight 1
+	enableArcs []*EnableArc
+}
+
+func (n *Net) structure() *structure {
+	st := &structure{
+		placePre:  make([][]int, len(n.places)),
+		placePost: make([][]int, len(n.places)),
+		transPre:  make([][]int, len(n.transitions)),
+		transPost: make([][]int, len(n.transitions)),
+		ordinary:  true,
+	}
+	for j, ti := range n.transitions {
+		t := ti.(*Transition)
+		for _, arc := range t.arcs_in {
+			if e, ok := arc.(*EnableArc); ok {
+				st.enableArcs = append(st.enableArcs, e)
+				continue
+			}
+			i := n.placeIdx[arc.Place()]
+			st.transPre[j] = appendUnique(st.transPre[j], i)
+			st.placePost[i] = appendUnique(st.placePost[i], j)
+			st.ordinary = st.ordinary && arcWeight(arc) == 1
+		}
+		for _, arc := range t.arcs_out {
+			i := n.placeIdx[arc.Place()]
+			st.transPost[j] = appendUnique(st.transPost[j], i)
+			st.placePre[i] = appendUnique(st.placePre[i], j)
+			st.ordinary = st.ordinary && arcWeight(arc) == 1
+		}
+	}
+	return st
+}
+
+func appendUnique(s []int, v int) []int {
+	if contains(s, v) {
+		return s
+	}
+	return append(s, v)
+}
+
+func contains(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Free-choice net: ordinary and, for every place with more output
+// transitions, the place is the only input of all of them.
+func (st *structure) isFreeChoice() bool {
+	if !st.ordinary {
+		return false
+	}
+	for _, post := range st.placePost {
+		if len(post) < 2 {
+			continue
+		}
+		for _, t := range post {
+			if len(st.transPre[t]) != 1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Test if some place of the net has a capacity
+func (n *Net) hasCapacities() bool {
+	for _, p := range n.places {
+		if p.Capacity() != undef {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package petrinet
+
+import (
+	"fmt"
+	"math/big"
+)
+
+/*
+Symbolic state space of a k-bounded net: reachable markings are encoded in a
+decision diagram (one level for every Place, holding 0..k tokens) instead of
+being enumerated one by one, so nets with a huge number of markings can be
+analyzed when their structure is regular.
+*/
+type SymbolicStateSpace struct {
+	net       *Net
+	d         *mdd
+	reachable int // mdd node
+}
+
+// Local effect of a transition on a Place
+type symbolicArc struct {
+	arcs     []ArcI // input arcs on place (enabling conditions)
+	delta    int    // tokens added by firing
+	capacity int    // place capacity (undef if unbounded)
+}
+
+// Transition as relation between decision diagram levels
+type symbolicTransition struct {
+	places  map[int]*symbolicArc // level -> local effect
+	last    int                  // deepest level touched
+	cache   map[int]int          // image cache
+	enCache map[int]int          // enabling states cache
+}
+
+// Compute every marking reachable from the current one, assuming that no
+// Place holds more than 'k' tokens: if a firing exceeds 'k' an error is returned.
+// Transitions are fired as relational products on the decision diagram
+// (chaining order), with the same semantic of Arc.IsEnabled and EnableArc.IsEnabled.
+// Guards (see 'SetGuard()') are not supported, since they are not local to a Place.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) SymbolicReachability(k int) (*SymbolicStateSpace, error) {
+	if n.hasGuards() {
+		return nil, fmt.Errorf("SymbolicReachability() failed for [%s]: guards are not supported", n.id)
+	}
+	s0 := n.currentState()
+	for i, toks := range s0 {
+		if toks > k {
+			return nil, fmt.Errorf("SymbolicReachability() failed for [%s]: place [%s] holds more than %d tokens", n.id, n.places[i].Id(), k)
+		}
+	}
+	d := newMdd(len(n.places), k)
+	relations := n.symbolicTransitions()
+	reachable := d.singleton(s0)
+	for {
+		old := reachable
+		for _, rel := range relations {
+			img, overflow := d.image(reachable, 0, rel)
+			if overflow >= 0 {
+				return nil, fmt.Errorf("SymbolicReachability() failed for [%s]: place [%s] can hold more than %d tokens", n.id, n.places[overflow].Id(), k)
+			}
+			reachable = d.union(reachable, img)
+		}
+		if reachable == old {
+			break // fixpoint reached
+		}
+	}
+	return &SymbolicStateSpace{net: n, d: d, reachable: reachable}, NoError
+}
+
+func (n *Net) symbolicTransitions() []*symbolicTransition {
+	relations := []*symbolicTransition{}
+	for _, ti := range n.transitions {
+		t := ti.(*Transition)
+		rel := &symbolicTransition{places: map[int]*symbolicArc{}, last: -1, cache: map[int]int{}, enCache: map[int]int{}}
+		local := func(i int) *symbolicArc {
+			if rel.places[i] == nil {
+				rel.places[i] = &symbolicArc{capacity: n.places[i].Capacity()}
+			}
+			if i > rel.last {
+				rel.last = i
+			}
+			return rel.places[i]
+		}
+		for _, arc := range t.arcs_in {
+			l := local(n.placeIdx[arc.Place()])
+			l.arcs = append(l.arcs, arc)
+			l.delta -= arcWeight(arc)
+		}
+		for _, arc := range t.arcs_out {
+			local(n.placeIdx[arc.Place()]).delta += arcWeight(arc)
+		}
+		relations = append(relations, rel)
+	}
+	return relations
+}
+
+// States reached firing transition from states in 'a' (at 'level').
+// Returns the level of a Place exceeding 'k' tokens, or -1.
+func (d *mdd) image(a, level int, rel *symbolicTransition) (int, int) {
+	if a == mddEmpty {
+		return mddEmpty, -1
+	}
+	if level > rel.last {
+		return a, -1 // transition doesn't touch remaining levels
+	}
+	if r, found := rel.cache[a]; found {
+		return r, -1
+	}
+	children := make([]int, d.k+1)
+	local := rel.places[level]
+	for v, child := range d.nodes[a].children {
+		if child == mddEmpty {
+			continue
+		}
+		target := v
+		if local != nil {
+			if !local.enabledBy(v) {
+				continue
+			}
+			target = v + local.delta
+		}
+		img, overflow := d.image(child, level+1, rel)
+		if overflow >= 0 {
+			return mddEmpty, overflow
+		}
+		if img == mddEmpty {
+			continue // transition not enabled by lower levels
+		}
+		if target > d.k {
+			return mddEmpty, level
+		}
+		children[target] = d.union(children[target], img)
+	}
+	r := d.node(level, children)
+	rel.cache[a] = r
+	return r, -1
+}
+
+func (l *symbolicArc) enabledBy(toks int) bool {
+	for _, arc := range l.arcs {
+		if !arcEnabledBy(arc, toks) {
+			return false
+		}
+	}
+	return l.capacity == undef || toks+l.delta <= l.capacity
+}
+
+// Number of reachable markings
+func (ss *SymbolicStateSpace) Count() *big.Int {
+	return ss.d.count(ss.reachable, map[int]*big.Int{})
+}
+
+// Test if marking is reachable. Places missing in marking are considered empty.
+func (ss *SymbolicStateSpace) Contains(m Marking) bool {
+	s := make(state, len(ss.net.places))
+	for i, p := range ss.net.places {
+		s[i] = m[p.Id()]
+	}
+	return ss.d.contains(ss.reachable, s)
+}
+
+// Number of reachable markings where no transition is enabled
+func (ss *SymbolicStateSpace) CountDeadlocks() *big.Int {
+	live := mddEmpty
+	for _, rel := range ss.net.symbolicTransitions() {
+		live = ss.d.union(live, ss.d.enabledIn(ss.reachable, 0, rel))
+	}
+	return ss.d.count(ss.d.minus(ss.reachable, live), map[int]*big.Int{})
+}
+
+// States in 'a' (at 'level') enabling the transition
+func (d *mdd) enabledIn(a, level int, rel *symbolicTransition) int {
+	if a == mddEmpty || level > rel.last {
+		return a
+	}
+	if r, found := rel.enCache[a]; found {
+		return r
+	}
+	children := make([]int, d.k+1)
+	local := rel.places[level]
+	for v, child := range d.nodes[a].children {
+		if local == nil || local.enabledBy(v) {
+			children[v] = d.enabledIn(child, level+1, rel)
+		}
+	}
+	r := d.node(level, children)
+	rel.enCache[a] = r
+	return r
+}
@@ -0,0 +1,61 @@
+package petrinet
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymbolicReachability(test *testing.T) {
+	// 2^30 markings: out of reach for explicit exploration
+	const N = 30
+	net := buildIndependentNet(N)
+
+	ss, err := net.SymbolicReachability(1)
+	assert.NoError(test, err)
+	assert.Equal(test, new(big.Int).Lsh(big.NewInt(1), N), ss.Count())
+	assert.Equal(test, big.NewInt(1), ss.CountDeadlocks())
+	m := Marking{"Q0": 1, "Q29": 1}
+	for i := 1; i < N-1; i++ {
+		m[fmt.Sprintf("P%d", i)] = 1
+	}
+	assert.True(test, ss.Contains(m))
+	m["P0"] = 1
+	assert.False(test, ss.Contains(m))
+}
+
+func TestSymbolicMatchesExplicit(test *testing.T) {
+	/* build net:
+
+	(P1)──2──►[T1]──►(P2)──►[T2]
+	            ●
+	(P3)──<0>───┘
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	p3 := net.NewPlace("P3")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p1.ConnectTo(t1, 2)
+	t1.ConnectTo(p2, 1)
+	t1.InhibitedBy(p3)
+	p2.ConnectTo(t2, 1)
+	p1.AddTokens(4)
+
+	g, err := net.ReachabilityGraph(0)
+	assert.NoError(test, err)
+	ss, err := net.SymbolicReachability(4)
+	assert.NoError(test, err)
+	assert.Equal(test, int64(len(g.Nodes)), ss.Count().Int64())
+	for _, node := range g.Nodes {
+		assert.True(test, ss.Contains(node.Marking))
+	}
+
+	// P2 can hold 2 tokens
+	_, err = net.SymbolicReachability(1)
+	assert.Error(test, err)
+}
@@ -0,0 +1,185 @@
+package petrinet
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Latest firing time of transitions that are never forced to fire
+const Forever = time.Duration(math.MaxInt64)
+
+// Source of time for timed nets
+type Clock interface {
+	// Time elapsed since clock creation
+	Now() time.Duration
+	// Let 'd' time elapse
+	Sleep(d time.Duration)
+}
+
+// Clock following wall-clock time
+type realClock struct {
+	start time.Time
+}
+
+func NewRealClock() Clock {
+	return &realClock{start: time.Now()}
+}
+func (c *realClock) Now() time.Duration {
+	return time.Since(c.start)
+}
+func (c *realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+// Discrete-event clock: time jumps forward without waiting
+type virtualClock struct {
+	mu  sync.Mutex
+	now time.Duration
+}
+
+func NewVirtualClock() Clock {
+	return &virtualClock{}
+}
+func (c *virtualClock) Now() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+func (c *virtualClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now += d
+}
+
+/*
+Time Petri net semantic (Merlin-Farber): a transition enabled at time 'θ' can
+fire at any time in [θ+eft, θ+lft] (see 'SetInterval()'), and must fire
+before θ+lft unless it's disabled by another firing.
+Untimed transitions have interval [0, Forever].
+A running net (see 'Start()') follows this semantic on the real clock: every
+transition fires at its earliest firing time, which meets its latest firing
+time as long as it gets its places in time. Enabling times are tracked when
+places tokens change, and a transition disabled in the meantime is enabled
+again from scratch. 'TimedStep()' follows the same semantic synchronously,
+with any clock.
+*/
+
+// Set static firing interval of transition (earliest and latest firing time)
+func (t *Transition) SetInterval(eft, lft time.Duration) {
+	if eft < 0 || lft < eft {
+		logger.Panicf("Transition [%s] cannot have interval [%v, %v]", t.id, eft, lft)
+	}
+	t.eft, t.lft = eft, lft
+}
+
+// Alias for SetInterval(d, d)
+func (t *Transition) SetDelay(d time.Duration) {
+	t.SetInterval(d, d)
+}
+
+// Enabled transitions among 'candidates' (their places must be locked) whose
+// earliest firing time has come. The others are notified again at that time
+// (in real time: running nets can't use a virtual clock).
+func (n *Net) readyAmong(candidates []TransitionI) []TransitionI {
+	ready := []TransitionI{}
+	now := n.clock.Now()
+	for _, ti := range n.enabledAmong(candidates) {
+		t := ti.(*Transition)
+		if at := t.enabledAt + t.eft; at > now {
+			if t.wakeAt != at {
+				t.wakeAt = at
+				time.AfterFunc(at-now, t.notifyReadiness)
+			}
+			continue
+		}
+		ready = append(ready, t)
+	}
+	return ready
+}
+
+// Forget enabling time of transition if it's disabled (its places must be locked)
+func (t *Transition) resetTimer() {
+	if !t.isEnabled() {
+		t.enabledAt = undef
+	}
+}
+
+// Set clock used by timed nets (a real clock by default)
+func (n *Net) SetClock(c Clock) {
+	n.clock = c
+	n.timers = nil
+}
+
+// Current time of net clock
+func (n *Net) Now() time.Duration {
+	return n.clock.Now()
+}
+
+// Synchronously fire one transition following time semantic: transitions
+// that can fire before any deadline (latest firing time of an enabled
+// transition) are candidates, net policy (see 'SetChooser()') picks one and
+// clock is moved forward to its earliest firing time.
+// Returns the transition fired and firing time, or nil if no transition is enabled.
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) TimedStep() (TransitionI, time.Duration) {
+	if n.timers == nil {
+		n.timers = map[*Transition]time.Duration{}
+	}
+	n.updateTimers(nil, nil) // tokens could be added between steps
+	if len(n.timers) == 0 {
+		return nil, n.clock.Now()
+	}
+	deadline := Forever
+	for t, since := range n.timers {
+		if t.lft != Forever && since+t.lft < deadline {
+			deadline = since + t.lft
+		}
+	}
+	candidates := []TransitionI{}
+	for _, ti := range n.transitions {
+		t := ti.(*Transition)
+		if since, enabled := n.timers[t]; enabled && since+t.eft <= deadline {
+			candidates = append(candidates, t)
+		}
+	}
+	t := n.choose(candidates).(*Transition)
+	if at, now := n.timers[t]+t.eft, n.clock.Now(); at > now {
+		n.clock.Sleep(at - now)
+	}
+	before := n.currentState()
+	if err := n.Fire(t); err != nil {
+		logger.Panicf("TimedStep() failed: %v", err)
+	}
+	n.updateTimers(t, before)
+	return t, n.clock.Now()
+}
+
+// Track enabling time of transitions after firing 'fired' from state 'before'
+// (if any). Transitions disabled by tokens consumed by 'fired' (and 'fired'
+// itself) are newly enabled.
+func (n *Net) updateTimers(fired *Transition, before state) {
+	now := n.clock.Now()
+	var intermediate state
+	if fired != nil {
+		intermediate = before.clone()
+		for _, arc := range fired.arcs_in {
+			intermediate[n.placeIdx[arc.Place()]] -= arcWeight(arc)
+		}
+	}
+	s := n.currentState()
+	for _, ti := range n.transitions {
+		t := ti.(*Transition)
+		if !n.isEnabledAt(t, s) {
+			delete(n.timers, t)
+			continue
+		}
+		_, persistent := n.timers[t]
+		if persistent && fired != nil {
+			persistent = t != fired && n.isEnabledAt(t, intermediate)
+		}
+		if !persistent {
+			n.timers[t] = now
+		}
+	}
+}
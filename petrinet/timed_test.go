@@ -0,0 +1,160 @@
+package petrinet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimedStep(test *testing.T) {
+	/* build net:
+
+	(P)──►[T1]──►(Q)──►[T2]──►(R)
+	      [2s]         [3s]
+
+	*/
+	net := NewNet("TestNet")
+	net.SetClock(NewVirtualClock())
+	p := net.NewPlace("P")
+	q := net.NewPlace("Q")
+	r := net.NewPlace("R")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p.ConnectTo(t1, 1)
+	t1.ConnectTo(q, 1)
+	q.ConnectTo(t2, 1)
+	t2.ConnectTo(r, 1)
+	t1.SetDelay(2 * time.Second)
+	t2.SetDelay(3 * time.Second)
+
+	p.AddTokens(1)
+	t, at := net.TimedStep()
+	assert.Equal(test, t1, t)
+	assert.Equal(test, 2*time.Second, at)
+	t, at = net.TimedStep()
+	assert.Equal(test, t2, t)
+	assert.Equal(test, 5*time.Second, at)
+	t, _ = net.TimedStep()
+	assert.Nil(test, t)
+	assert.Equal(test, 5*time.Second, net.Now())
+}
+
+func TestTimedStepIntervals(test *testing.T) {
+	/* build net (transitions compete for P):
+
+	     ┌──►[T1] [0s,5s]
+	(P)──┼──►[T2] [3s,4s]
+	     └──►[T3] [6s,8s]
+
+	*/
+	build := func() (*Net, []TransitionI) {
+		net := NewNet("TestNet")
+		net.SetClock(NewVirtualClock())
+		p := net.NewPlace("P")
+		ts := []TransitionI{}
+		for _, id := range []string{"T1", "T2", "T3"} {
+			t := net.NewTransition(id)
+			p.ConnectTo(t, 1)
+			ts = append(ts, t)
+		}
+		ts[0].SetInterval(0, 5*time.Second)
+		ts[1].SetInterval(3*time.Second, 4*time.Second)
+		ts[2].SetInterval(6*time.Second, 8*time.Second)
+		p.AddTokens(1)
+		return net, ts
+	}
+	net, ts := build()
+	t, at := net.TimedStep()
+	assert.Equal(test, ts[0], t)
+	assert.Equal(test, time.Duration(0), at)
+
+	// T3 can't wait beyond deadline of T2
+	net, ts = build()
+	ts[1].SetPriority(1)
+	ts[2].SetPriority(2)
+	net.SetChooser(NewPriorityChooser(nil))
+	t, at = net.TimedStep()
+	assert.Equal(test, ts[1], t)
+	assert.Equal(test, 3*time.Second, at)
+}
+
+func TestTimedStepPersistence(test *testing.T) {
+	/* build net:
+
+	(A) ◄──► [Tick] [1s]
+
+	(B)──►[Tb] [2.5s]
+
+	*/
+	net := NewNet("TestNet")
+	net.SetClock(NewVirtualClock())
+	a := net.NewPlace("A")
+	b := net.NewPlace("B")
+	tick := net.NewTransition("Tick")
+	tb := net.NewTransition("Tb")
+	a.ConnectTo(tick, 1)
+	tick.ConnectTo(a, 1)
+	b.ConnectTo(tb, 1)
+	tick.SetDelay(time.Second)
+	tb.SetDelay(2500 * time.Millisecond)
+	a.AddTokens(1)
+	b.AddTokens(1)
+
+	// Tb stays enabled while Tick fires
+	expected := []struct {
+		t  TransitionI
+		at time.Duration
+	}{
+		{tick, time.Second},
+		{tick, 2 * time.Second},
+		{tb, 2500 * time.Millisecond},
+		{tick, 3 * time.Second},
+	}
+	for _, e := range expected {
+		t, at := net.TimedStep()
+		assert.Equal(test, e.t, t)
+		assert.Equal(test, e.at, at)
+	}
+}
+
+func TestTimedRunningNet(test *testing.T) {
+	disableLogger()
+	/* build net:
+
+	(P)──►[T]──►(PEnd)
+	      [50ms]
+
+	*/
+	net := NewNet("TestNet")
+	p := net.NewPlace("P")
+	pEnd := net.NewPlace("PEnd")
+	t := net.NewTransition("T")
+	p.ConnectTo(t, 1)
+	t.ConnectTo(pEnd, 1)
+	t.SetDelay(50 * time.Millisecond)
+	fired := make(chan time.Time, 2)
+	t.OnFire(func(e FiringEvent) {
+		fired <- time.Now()
+	})
+
+	// running net waits in real time
+	net.SetClock(NewVirtualClock())
+	assert.NotEqual(test, NoError, net.Start())
+
+	net.SetClock(NewRealClock())
+	assert.Equal(test, NoError, net.Start())
+	enabled := time.Now()
+	p.AddTokens(1)
+	assert.True(test, (<-fired).Sub(enabled) >= 50*time.Millisecond)
+
+	// disabled before its delay, T is enabled again from scratch
+	p.AddTokens(1)
+	time.Sleep(20 * time.Millisecond)
+	p.AddTokens(-1)
+	enabled = time.Now()
+	p.AddTokens(1)
+	assert.True(test, (<-fired).Sub(enabled) >= 50*time.Millisecond)
+	net.Stop()
+	assert.Equal(test, 2, pEnd.Tokens())
+}
@@ -2,6 +2,8 @@ package petrinet
 
 import (
 	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/golang-collections/collections/set"
 )
@@ -19,6 +21,21 @@ type TransitionI interface {
 	SetHigh(high int) func(*EnableArc)
 	// Alias for EnabledBy(p, SetLow(0), SetHigh(0))
 	InhibitedBy(p PlaceI)
+	// Set priority used by priority policy (higher wins, default 0)
+	SetPriority(priority int)
+	Priority() int
+	// Set relative weight used by weighted random policy (default 1)
+	SetWeight(weight float64)
+	// Set static firing interval of timed nets (default [0, Forever])
+	SetInterval(eft, lft time.Duration)
+	// Alias for SetInterval(d, d)
+	SetDelay(d time.Duration)
+	// Set exponential firing rate of stochastic nets ('Immediate' for transitions firing in zero time)
+	SetRate(rate float64)
+	// Enable transition only when 'guard' holds on the marking of connected places
+	SetGuard(guard func(Marking) bool)
+	// Invoke 'action' after every firing of transition
+	OnFire(action func(FiringEvent))
 
 	isConnectedToPlace(p PlaceI) bool
 	notifyReadiness()
@@ -36,6 +53,15 @@ type Transition struct {
 	arcs_in      []ArcI
 	arcs_out     []ArcI
 	notification chan bool
+	priority     int
+	weight       float64
+	enabledSince uint64        // net tick when transition was found enabled (0 if not, atomic)
+	enabledAt    time.Duration // net clock time when transition was enabled (undef if not)
+	wakeAt       time.Duration // earliest firing time a running transition is waiting for
+	eft, lft     time.Duration // static firing interval (see 'SetInterval()')
+	rate         float64       // exponential firing rate (see 'SetRate()', undef if unset)
+	guard        func(Marking) bool
+	actions      []func(FiringEvent)
 }
 
 // Transition constructor
@@ -46,6 +72,11 @@ func newTransition(n *Net, id string) *Transition {
 		arcs_in:      []ArcI{},
 		arcs_out:     []ArcI{},
 		notification: make(chan bool, 1),
+		weight:       1,
+		lft:          Forever,
+		rate:         undef,
+		enabledAt:    undef,
+		wakeAt:       undef,
 	}
 	return &t
 }
@@ -107,6 +138,15 @@ func lockPlaces(t *Transition, places *set.Set) {
 	}
 }
 
+// Places of a set, as a slice
+func placesOf(places *set.Set) []PlaceI {
+	ps := []PlaceI{}
+	places.Do(func(i interface{}) {
+		ps = append(ps, i.(PlaceI))
+	})
+	return ps
+}
+
 // Unlocks all places
 func unlockPlaces(t *Transition, places *set.Set) {
 	places.Do(func(i interface{}) {
@@ -114,13 +154,50 @@ func unlockPlaces(t *Transition, places *set.Set) {
 		place.unlock()
 	})
 }
-func consumeInTokens(t *Transition) bool {
-	// verify if tokens can be consumed
+
+// Test if tokens can be consumed (places must be locked)
+func (t *Transition) isEnabled() bool {
 	for _, arc := range t.arcs_in {
 		if !arc.IsEnabled() { // input place has not enought tokens
 			return false
 		}
 	}
+	if t.exceedsCapacity(PlaceI.Tokens) != nil {
+		return false
+	}
+	return t.guard == nil || t.guard(t.localMarking())
+}
+
+// Output place whose capacity would be exceeded by firing, when places hold
+// 'tokens' (nil if none)
+func (t *Transition) exceedsCapacity(tokens func(PlaceI) int) PlaceI {
+	for _, out := range t.arcs_out {
+		p := out.Place()
+		if p.Capacity() == undef {
+			continue
+		}
+		after := tokens(p)
+		for _, arc := range t.arcs_in {
+			if arc.Place() == p {
+				after -= arcWeight(arc)
+			}
+		}
+		for _, arc := range t.arcs_out {
+			if arc.Place() == p {
+				after += arcWeight(arc)
+			}
+		}
+		if after > p.Capacity() {
+			return p
+		}
+	}
+	return nil
+}
+func consumeInTokens(t *Transition) bool {
+	// verify if tokens can be consumed
+	if !t.isEnabled() {
+		return false
+	}
 	// finally consume tokens
 	for _, arc := range t.arcs_in {
 		arc.ConsumeTokens()
@@ -141,25 +218,163 @@ func uniquePlaces(t *Transition) *set.Set {
 	return uniques
 }
 
-// Firing operation in a transactional (atomic) way
+// Firing operation in a transactional (atomic) way.
+// If net has a policy (see 'Net.SetChooser()') the winner among enabled
+// transitions in conflict with 't' is fired instead.
 func firingAttempt(t *Transition) bool {
+	t.net.chooserMu.Lock()
+	policy := t.net.chooser != nil
+	t.net.chooserMu.Unlock()
+	if policy {
+		return policyFiringAttempt(t)
+	}
+	return fireTransition(t, true)
+}
+
+// Atomically fire transition (if enabled and, when 'timed', if its earliest
+// firing time has come, see 'readyAmong()')
+func fireTransition(t *Transition, timed bool) bool {
 	all_places := uniquePlaces(t)
 	// Firing () must be executed as an atomic operation to guarantee consistency.
 	// That's why, first of all, places are locked.
 	lockPlaces(t, all_places)
-	defer unlockPlaces(t, all_places)
+	var event *FiringEvent
+	if !timed || len(t.net.readyAmong([]TransitionI{t})) > 0 {
+		event = fireLocked(t, all_places)
+	}
+	if event != nil {
+		refreshEnabling(placesOf(all_places), all_places)
+	}
+	unlockPlaces(t, all_places)
 
+	dispatch(event)
+	return event != nil
+}
+
+// Fire transition (if enabled) with all its places already locked ('held').
+// Returns the firing event for actions, nil if transition is not enabled.
+func fireLocked(t *Transition, held *set.Set) *FiringEvent {
 	preDot := t.net.buildDot(t)
-	ok := consumeInTokens(t)
-	if ok {
-		for _, arc := range t.arcs_out {
-			arc.FireTokens()
+	var before Marking
+	if len(t.actions) > 0 {
+		before = t.localMarking()
+	}
+	if !consumeInTokens(t) {
+		return nil
+	}
+	// transitions disabled by consumed tokens are newly enabled by produced
+	// ones (time semantic), 't' included
+	inputs := []PlaceI{}
+	for _, arc := range t.arcs_in {
+		inputs = append(inputs, arc.Place())
+	}
+	forConnected(inputs, held, (*Transition).resetTimer)
+	atomic.StoreUint64(&t.enabledSince, 0)
+	t.enabledAt = undef
+	for _, arc := range t.arcs_out {
+		arc.FireTokens()
+	}
+	postDot := t.net.buildDot()
+
+	t.net.addAnimationFrame([]frame{{preDot, 200}, {postDot, 200}})
+	return t.firingEvent(before)
+}
+
+// Transitions connected to 't' through chains of transitions consuming tokens
+// from a shared place ('t' included, in net order)
+func conflictCluster(t *Transition) []*Transition {
+	return t.net.cluster(t, t.net.transitions)
+}
+
+// Transitions among 'ts' connected to 't' through conflicts between transitions
+// of 'ts' (in the order of 'ts', empty if 't' is not among them)
+func (n *Net) cluster(t *Transition, ts []TransitionI) []*Transition {
+	in := map[*Transition]bool{}
+	for _, u := range ts {
+		in[u.(*Transition)] = true
+	}
+	if !in[t] {
+		return []*Transition{}
+	}
+	reached := map[*Transition]bool{t: true}
+	queue := []*Transition{t}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, ui := range ts {
+			if u := ui.(*Transition); !reached[u] && inConflict(v, u) {
+				reached[u] = true
+				queue = append(queue, u)
+			}
+		}
+	}
+	cluster := []*Transition{}
+	for _, u := range ts {
+		if reached[u.(*Transition)] {
+			cluster = append(cluster, u.(*Transition))
+		}
+	}
+	return cluster
+}
+
+// Test if transitions consume tokens from a shared place
+func inConflict(t, u *Transition) bool {
+	for _, a := range t.arcs_in {
+		if _, ok := a.(*Arc); ok && u.consumesFrom(a.Place()) {
+			return true
+		}
+	}
+	return false
+}
+func (t *Transition) consumesFrom(p PlaceI) bool {
+	for _, a := range t.arcs_in {
+		if _, ok := a.(*Arc); ok && a.Place() == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Lock places of the conflict cluster of 't' and let net policy pick the
+// transition to fire among the ready ones (see 'readyAmong()') connected to 't' by conflicts
+// between enabled transitions (so that 't' cannot win over a transition
+// blocking it from outside its direct conflicts).
+// Returns true if 't' is fired.
+func policyFiringAttempt(t *Transition) bool {
+	conflict := conflictCluster(t)
+	all_places := set.New()
+	for _, u := range conflict {
+		uniquePlaces(u).Do(func(p interface{}) {
+			all_places.Insert(p)
+		})
+	}
+	lockPlaces(t, all_places)
+
+	candidates := make([]TransitionI, len(conflict))
+	for k, u := range conflict {
+		candidates[k] = u
+	}
+	var winner *Transition
+	var event *FiringEvent
+	if competing := t.net.cluster(t, t.net.readyAmong(candidates)); len(competing) > 0 {
+		enabled := make([]TransitionI, len(competing))
+		for k, u := range competing {
+			enabled[k] = u
 		}
-		postDot := t.net.buildDot(nil)
+		winner = t.net.choose(enabled).(*Transition)
+		event = fireLocked(winner, all_places)
+	}
+	if event != nil {
+		refreshEnabling(placesOf(all_places), all_places)
+	}
+	unlockPlaces(t, all_places)
 
-		t.net.addAnimationFrame([]frame{{preDot, 200}, {postDot, 200}})
+	dispatch(event)
+	if winner != nil && winner != t {
+		// places of 't' may be untouched by the winner: try again
+		t.notifyReadiness()
 	}
-	return ok
+	return winner == t && event != nil
 }
 func execute(t *Transition) {
 	for {
@@ -235,3 +450,15 @@ func (t *Transition) EnabledBy(p PlaceI, params ...func(*EnableArc)) {
 func (t *Transition) InhibitedBy(p PlaceI) {
 	t.EnabledBy(p, t.SetLow(0), t.SetHigh(0))
 }
+func (t *Transition) SetPriority(priority int) {
+	t.priority = priority
+}
+func (t *Transition) Priority() int {
+	return t.priority
+}
+func (t *Transition) SetWeight(weight float64) {
+	if weight <= 0 {
+		logger.Panicf("Transition [%s] cannot have weight %v", t.id, weight)
+	}
+	t.weight = weight
+}
@@ -0,0 +1,457 @@
+package petrinet
+
+import (
+	"container/heap"
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+/*
+Complete finite prefix of the unfolding (branching process) of a safe net.
+Conditions are occurrences of places, events are occurrences of transitions:
+two events are concurrent when neither causes the other and they don't
+compete for a condition, so true concurrency is explicit.
+Events are added following the ERV adequate order (size, Parikh vector and
+Foata normal form of local configurations). An event is a cut-off when its
+local configuration reaches a marking already reached by a smaller one: its
+consequences are not unfolded.
+*/
+type Unfolding struct {
+	Conditions []*Condition
+	Events     []*Event
+	net        *Net
+	place      []int // condition -> place
+}
+
+type Condition struct {
+	Id    int
+	Place string // place id
+	Pre   int    // id of producing event (-1 for initial conditions)
+}
+
+type Event struct {
+	Id         int
+	Transition string // transition id
+	Preset     []int  // consumed condition ids
+	Postset    []int  // produced condition ids
+	CutOff     bool
+}
+
+// Build complete finite prefix of the unfolding from the current marking.
+// The net must be safe, with weight 1 Arcs and without EnableArcs, guards or capacities.
+// Construction is bounded by 'limit' events (see package doc).
+// NB: do not use while net is running (i.e. between 'Start()' and 'Stop()')
+func (n *Net) Unfold(limit int) (*Unfolding, error) {
+	st := n.structure()
+	if !st.ordinary || len(st.enableArcs) > 0 {
+		return nil, fmt.Errorf("Unfold() failed for [%s]: only weight 1 arcs are supported", n.id)
+	}
+	if n.hasGuards() || n.hasCapacities() {
+		return nil, fmt.Errorf("Unfold() failed for [%s]: guards and capacities are not supported", n.id)
+	}
+	for j, pre := range st.transPre {
+		if len(pre) == 0 {
+			return nil, fmt.Errorf("Unfold() failed for [%s]: transition [%s] has no input place", n.id, n.transitions[j].Id())
+		}
+	}
+	u := &unfolder{
+		net:     n,
+		st:      st,
+		c:       n.IncidenceMatrix().C,
+		m0:      n.currentState(),
+		result:  &Unfolding{net: n},
+		seen:    map[string]bool{},
+		reached: map[string]bool{},
+	}
+	// initial conditions are pairwise concurrent
+	initial := []int{}
+	co := bitset{}
+	for i, toks := range u.m0 {
+		if toks > 1 {
+			return nil, fmt.Errorf("Unfold() failed for [%s]: place [%s] is not safe", n.id, n.places[i].Id())
+		}
+		if toks == 1 {
+			c := u.addCondition(i, -1, co.clone())
+			co.set(c)
+			initial = append(initial, c)
+		}
+	}
+	u.reached[u.m0.key()] = true
+	u.findExtensions(initial)
+
+	for u.queue.Len() > 0 {
+		if limit > 0 && len(u.result.Events) >= limit {
+			return u.result, fmt.Errorf("Unfold() for [%s] stopped after %d events", n.id, limit)
+		}
+		if err := u.addEvent(heap.Pop(&u.queue).(*extension)); err != nil {
+			return u.result, err
+		}
+	}
+	return u.result, NoError
+}
+
+// Occurrence net of the prefix, to be rendered as any other net (e.g. with 'SavePng()').
+// Condition 'c<id>_<place>' is an occurrence of place, event 'e<id>_<transition>'
+// an occurrence of transition ('_cutoff' suffix for cut-off events).
+// Minimal conditions are marked with a token.
+func (u *Unfolding) OccurrenceNet() *Net {
+	occ := NewNet(u.net.id + "_unfolding")
+	places := make([]PlaceI, len(u.Conditions))
+	for i, c := range u.Conditions {
+		places[i] = occ.NewPlace(fmt.Sprintf("c%d_%s", c.Id, c.Place))
+		if c.Pre < 0 {
+			places[i].AddTokens(1)
+		}
+	}
+	for _, e := range u.Events {
+		id := fmt.Sprintf("e%d_%s", e.Id, e.Transition)
+		if e.CutOff {
+			id += "_cutoff"
+		}
+		t := occ.NewTransition(id)
+		for _, c := range e.Preset {
+			places[c].ConnectTo(t, 1)
+		}
+		for _, c := range e.Postset {
+			t.ConnectTo(places[c], 1)
+		}
+	}
+	return occ
+}
+
+// Find every reachable dead marking of the original net, exploring
+// configurations of the prefix without cut-off events (prefix is complete,
+// so they reach every reachable marking).
+// Sequence of each Deadlock is a shortest firing sequence of the prefix.
+func (u *Unfolding) Deadlocks() []Deadlock {
+	n := u.net
+	// cut of a configuration: set of conditions marked
+	cut := bitset{}
+	for _, c := range u.Conditions {
+		if c.Pre < 0 {
+			cut.set(c.Id)
+		}
+	}
+	cuts := []bitset{cut}
+	paths := [][]string{{}}
+	visited := map[string]bool{cut.key(): true}
+	found := map[string]bool{}
+	deadlocks := []Deadlock{}
+	for i := 0; i < len(cuts); i++ {
+		cut := cuts[i]
+		s := make(state, len(n.places))
+		cut.each(func(c int) {
+			s[u.place[c]]++
+		})
+		if n.isDead(s) && !found[s.key()] {
+			found[s.key()] = true
+			deadlocks = append(deadlocks, Deadlock{n.toMarking(s), paths[i]})
+		}
+		for _, e := range u.Events {
+			if e.CutOff || !cut.hasAll(e.Preset) {
+				continue
+			}
+			next := cut.clone()
+			for _, c := range e.Preset {
+				next.clear(c)
+			}
+			for _, c := range e.Postset {
+				next.set(c)
+			}
+			if key := next.key(); !visited[key] {
+				visited[key] = true
+				cuts = append(cuts, next)
+				path := append(append([]string{}, paths[i]...), e.Transition)
+				paths = append(paths, path)
+			}
+		}
+	}
+	return deadlocks
+}
+
+type unfolder struct {
+	net     *Net
+	st      *structure
+	c       [][]int // incidence matrix
+	m0      state
+	result  *Unfolding
+	co      []bitset        // condition -> concurrent conditions
+	dead    []bool          // condition produced by a cut-off event
+	trans   []int           // event -> transition
+	cause   []bitset        // event -> local configuration (event ids)
+	depth   []int           // event -> depth in causality
+	queue   extensionQueue  // possible extensions ordered by ERV order
+	seen    map[string]bool // possible extensions already found
+	reached map[string]bool // markings of local configurations already added
+}
+
+// Possible extension of the prefix: transition with a co-set of conditions
+type extension struct {
+	u      *unfolder
+	t      int
+	preset []int
+	config bitset // local configuration, without the extension itself
+	parikh []int  // Parikh vector of local configuration (extension included)
+	size   int    // size of local configuration (extension included)
+	depth  int
+}
+
+func (u *unfolder) addCondition(place, pre int, co bitset) int {
+	id := len(u.result.Conditions)
+	u.result.Conditions = append(u.result.Conditions, &Condition{Id: id, Place: u.net.places[place].Id(), Pre: pre})
+	u.result.place = append(u.result.place, place)
+	u.co = append(u.co, co)
+	u.dead = append(u.dead, false)
+	// co relation is symmetric
+	for other := range u.co {
+		if co.has(other) {
+			u.co[other].set(id)
+		}
+	}
+	return id
+}
+
+func (u *unfolder) addEvent(x *extension) error {
+	id := len(u.result.Events)
+	e := &Event{Id: id, Transition: u.net.transitions[x.t].Id(), Preset: x.preset}
+	u.result.Events = append(u.result.Events, e)
+	config := x.config.clone()
+	config.set(id)
+	u.trans = append(u.trans, x.t)
+	u.cause = append(u.cause, config)
+	u.depth = append(u.depth, x.depth)
+
+	// marking of local configuration: M0 + C·parikh
+	m := u.m0.clone()
+	for i := range m {
+		for j, count := range x.parikh {
+			m[i] += u.c[i][j] * count
+		}
+		if m[i] > 1 {
+			return fmt.Errorf("Unfold() failed for [%s]: place [%s] is not safe", u.net.id, u.net.places[i].Id())
+		}
+	}
+	// smaller local configurations (or the empty one) reach the same marking
+	e.CutOff = u.reached[m.key()]
+	u.reached[m.key()] = true
+
+	// conditions produced are concurrent with conditions concurrent to the whole preset
+	co := u.co[x.preset[0]].clone()
+	for _, c := range x.preset[1:] {
+		co = co.and(u.co[c])
+	}
+	first := len(u.result.Conditions)
+	for k := range u.st.transPost[x.t] {
+		e.Postset = append(e.Postset, first+k)
+	}
+	for k, p := range u.st.transPost[x.t] {
+		cco := co.clone()
+		for _, sibling := range e.Postset {
+			if sibling != e.Postset[k] {
+				cco.set(sibling)
+			}
+		}
+		u.addCondition(p, id, cco)
+		u.dead[e.Postset[k]] = e.CutOff
+	}
+	if !e.CutOff {
+		u.findExtensions(e.Postset)
+	}
+	return NoError
+}
+
+// Find possible extensions using at least one of 'conds'
+func (u *unfolder) findExtensions(conds []int) {
+	for _, c := range conds {
+		for _, t := range u.st.placePost[u.result.place[c]] {
+			u.coSets(t, c, 0, make([]int, len(u.st.transPre[t])))
+		}
+	}
+}
+
+// Choose a condition for every input place of 't' (from position 'k'),
+// with 'c' chosen for its place and every condition pairwise concurrent.
+func (u *unfolder) coSets(t, c, k int, preset []int) {
+	if k == len(preset) {
+		u.addExtension(t, preset)
+		return
+	}
+	p := u.st.transPre[t][k]
+	if p == u.result.place[c] {
+		preset[k] = c
+		u.coSets(t, c, k+1, preset)
+		return
+	}
+	for d := range u.result.Conditions {
+		if u.dead[d] || u.result.place[d] != p || !u.co[c].has(d) {
+			continue
+		}
+		concurrent := true
+		for _, other := range preset[:k] {
+			if other != c && !u.co[other].has(d) {
+				concurrent = false
+				break
+			}
+		}
+		if concurrent {
+			preset[k] = d
+			u.coSets(t, c, k+1, preset)
+		}
+	}
+}
+
+func (u *unfolder) addExtension(t int, preset []int) {
+	sorted := append([]int{}, preset...)
+	sort.Ints(sorted)
+	key := fmt.Sprintf("%d:%v", t, sorted)
+	if u.seen[key] {
+		return
+	}
+	u.seen[key] = true
+
+	x := &extension{u: u, t: t, preset: sorted, config: bitset{}}
+	for _, c := range sorted {
+		if pre := u.result.Conditions[c].Pre; pre >= 0 {
+			x.config = x.config.or(u.cause[pre])
+			if u.depth[pre] > x.depth {
+				x.depth = u.depth[pre]
+			}
+		}
+	}
+	x.depth++
+	x.size = x.config.count() + 1
+	x.parikh = make([]int, len(u.net.transitions))
+	x.parikh[t]++
+	x.config.each(func(e int) {
+		x.parikh[u.trans[e]]++
+	})
+	heap.Push(&u.queue, x)
+}
+
+// ERV adequate order on local configurations
+func (x *extension) less(y *extension) bool {
+	if x.size != y.size {
+		return x.size < y.size
+	}
+	if c := compareParikh(x.parikh, y.parikh); c != 0 {
+		return c < 0
+	}
+	// Foata normal form: compare Parikh vectors level by level
+	fx, fy := x.foata(), y.foata()
+	for level := 0; level < len(fx) && level < len(fy); level++ {
+		if c := compareParikh(fx[level], fy[level]); c != 0 {
+			return c < 0
+		}
+	}
+	return len(fx) < len(fy)
+}
+
+// Lexicographic order on words made of sorted transitions: at the first
+// transition with different occurrences, the vector with more occurrences is smaller.
+func compareParikh(a, b []int) int {
+	for j := range a {
+		if a[j] != b[j] {
+			if a[j] > b[j] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Parikh vector of every level (events at the same depth) of local configuration
+func (x *extension) foata() [][]int {
+	levels := make([][]int, x.depth)
+	for k := range levels {
+		levels[k] = make([]int, len(x.parikh))
+	}
+	x.config.each(func(e int) {
+		levels[x.u.depth[e]-1][x.u.trans[e]]++
+	})
+	levels[x.depth-1][x.t]++
+	return levels
+}
+
+type extensionQueue []*extension
+
+func (q extensionQueue) Len() int            { return len(q) }
+func (q extensionQueue) Less(i, j int) bool  { return q[i].less(q[j]) }
+func (q extensionQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *extensionQueue) Push(x interface{}) { *q = append(*q, x.(*extension)) }
+func (q *extensionQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// Set of non-negative integers
+type bitset []uint64
+
+func (b *bitset) set(i int) {
+	for len(*b) <= i/64 {
+		*b = append(*b, 0)
+	}
+	(*b)[i/64] |= 1 << (uint(i) % 64)
+}
+func (b bitset) has(i int) bool {
+	return i/64 < len(b) && b[i/64]&(1<<(uint(i)%64)) != 0
+}
+func (b bitset) clear(i int) {
+	if i/64 < len(b) {
+		b[i/64] &^= 1 << (uint(i) % 64)
+	}
+}
+func (b bitset) hasAll(s []int) bool {
+	for _, i := range s {
+		if !b.has(i) {
+			return false
+		}
+	}
+	return true
+}
+func (b bitset) clone() bitset {
+	return append(bitset{}, b...)
+}
+func (b bitset) and(o bitset) bitset {
+	r := bitset{}
+	for i := 0; i < len(b) && i < len(o); i++ {
+		r = append(r, b[i]&o[i])
+	}
+	return r
+}
+func (b bitset) or(o bitset) bitset {
+	r := b.clone()
+	for i, w := range o {
+		if i < len(r) {
+			r[i] |= w
+		} else {
+			r = append(r, w)
+		}
+	}
+	return r
+}
+func (b bitset) key() string {
+	for len(b) > 0 && b[len(b)-1] == 0 {
+		b = b[:len(b)-1]
+	}
+	return fmt.Sprint([]uint64(b))
+}
+func (b bitset) count() int {
+	c := 0
+	for _, w := range b {
+		c += bits.OnesCount64(w)
+	}
+	return c
+}
+func (b bitset) each(f func(int)) {
+	for i, w := range b {
+		for w != 0 {
+			k := bits.TrailingZeros64(w)
+			f(i*64 + k)
+			w &= w - 1
+		}
+	}
+}
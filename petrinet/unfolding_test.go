@@ -0,0 +1,140 @@
+package petrinet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnfoldConcurrency(test *testing.T) {
+	/* build net:
+
+	(P0)──►[T0]──►(Q0)
+
+	(P1)──►[T1]──►(Q1)
+
+	*/
+	net := buildIndependentNet(2)
+	u, err := net.Unfold(0)
+	assert.NoError(test, err)
+	assert.Equal(test, 4, len(u.Conditions))
+	assert.Equal(test, 2, len(u.Events))
+	for _, e := range u.Events {
+		assert.False(test, e.CutOff)
+	}
+	// only one dead marking, whatever the interleaving
+	deadlocks := u.Deadlocks()
+	assert.Equal(test, 1, len(deadlocks))
+	assert.Equal(test, Marking{"P0": 0, "P1": 0, "Q0": 1, "Q1": 1}, deadlocks[0].Marking)
+}
+
+func TestUnfoldCutOff(test *testing.T) {
+	/* build net:
+
+	(P1)──►[T1]──►(P2)
+	  ▲             │
+	  └────[T2]◄────┘
+
+	*/
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	t1 := net.NewTransition("T1")
+	t2 := net.NewTransition("T2")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+	p2.ConnectTo(t2, 1)
+	t2.ConnectTo(p1, 1)
+
+	p1.AddTokens(1)
+	u, err := net.Unfold(0)
+	assert.NoError(test, err)
+	assert.Equal(test, 2, len(u.Events))
+	assert.False(test, u.Events[0].CutOff)
+	assert.True(test, u.Events[1].CutOff) // back to initial marking
+	assert.Equal(test, 0, len(u.Deadlocks()))
+
+	occ := u.OccurrenceNet()
+	assert.Equal(test, 3, len(occ.places))
+	assert.Equal(test, "e1_T2_cutoff", occ.transitions[1].Id())
+	assert.True(test, strings.Contains(occ.buildDot(), "c0_P1"))
+}
+
+func TestUnfoldDeadlocks(test *testing.T) {
+	/* build net (two processes taking forks in opposite order):
+
+	(I1)──►[A1]──►(H1)──►[B1]──►(E1)──►[R1]──► (I1),(F1),(F2)
+	        ▲             ▲
+	(F1)────┘     (F2)────┘
+
+	(I2)──►[A2]──►(H2)──►[B2]──►(E2)──►[R2]──► (I2),(F1),(F2)
+	        ▲             ▲
+	(F2)────┘     (F1)────┘
+
+	*/
+	net := NewNet("TestNet")
+	f1 := net.NewPlace("F1")
+	f2 := net.NewPlace("F2")
+	for _, proc := range []struct {
+		id          string
+		first, next PlaceI
+	}{{"1", f1, f2}, {"2", f2, f1}} {
+		idle := net.NewPlace("I" + proc.id)
+		hold := net.NewPlace("H" + proc.id)
+		eat := net.NewPlace("E" + proc.id)
+		a := net.NewTransition("A" + proc.id)
+		b := net.NewTransition("B" + proc.id)
+		r := net.NewTransition("R" + proc.id)
+		idle.ConnectTo(a, 1)
+		proc.first.ConnectTo(a, 1)
+		a.ConnectTo(hold, 1)
+		hold.ConnectTo(b, 1)
+		proc.next.ConnectTo(b, 1)
+		b.ConnectTo(eat, 1)
+		eat.ConnectTo(r, 1)
+		r.ConnectTo(idle, 1)
+		r.ConnectTo(f1, 1)
+		r.ConnectTo(f2, 1)
+		idle.AddTokens(1)
+	}
+	f1.AddTokens(1)
+	f2.AddTokens(1)
+
+	u, err := net.Unfold(0)
+	assert.NoError(test, err)
+	assert.Equal(test, 6, len(u.Events))
+	cutoffs := 0
+	for _, e := range u.Events {
+		if e.CutOff {
+			cutoffs++
+			assert.True(test, e.Transition == "R1" || e.Transition == "R2")
+		}
+	}
+	assert.Equal(test, 2, cutoffs)
+
+	deadlocks := u.Deadlocks()
+	report, err := net.Deadlocks(0)
+	assert.NoError(test, err)
+	assert.Equal(test, 1, len(deadlocks))
+	assert.Equal(test, report.Deadlocks[0].Marking, deadlocks[0].Marking)
+	assert.ElementsMatch(test, []string{"A1", "A2"}, deadlocks[0].Sequence)
+}
+
+func TestUnfoldErrors(test *testing.T) {
+	net := NewNet("TestNet")
+	p1 := net.NewPlace("P1")
+	p2 := net.NewPlace("P2")
+	t1 := net.NewTransition("T1")
+	p1.ConnectTo(t1, 1)
+	t1.ConnectTo(p2, 1)
+
+	p1.AddTokens(2)
+	_, err := net.Unfold(0)
+	assert.Error(test, err) // not safe
+
+	t2 := net.NewTransition("T2")
+	t2.EnabledBy(p2)
+	_, err = net.Unfold(0)
+	assert.Error(test, err) // EnableArc
+}